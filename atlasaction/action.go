@@ -17,6 +17,7 @@ import (
 	"io"
 	"iter"
 	"maps"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -69,6 +70,11 @@ type (
 		SchemaApply(context.Context, *atlasexec.SchemaApply)
 		SchemaLint(context.Context, *SchemaLintReport)
 	}
+	// TestReporter is an optional interface for reporting the results
+	// of the test actions, e.g. "migrate/test" and "schema/test".
+	TestReporter interface {
+		TestResults(context.Context, *TestReport)
+	}
 	// SCMClient contains methods for interacting with SCM platforms (GitHub, Gitlab etc...).
 	SCMClient interface {
 		// PullRequest returns information about a pull request.
@@ -86,6 +92,16 @@ type (
 		// CommentSchemaLint comments on the pull request with the schema lint report.
 		CommentSchemaLint(context.Context, *TriggerContext, *SchemaLintReport) error
 	}
+	// SCMChecker is an optional interface for SCM clients that can publish
+	// the results as a check on the pull request commit (e.g. GitHub Check Runs).
+	SCMChecker interface {
+		// CheckLint publishes the lint report as a check.
+		CheckLint(context.Context, *TriggerContext, *atlasexec.SummaryReport) error
+		// CheckPlan publishes the schema plan as a check.
+		CheckPlan(context.Context, *TriggerContext, *atlasexec.SchemaPlan) error
+		// CheckSchemaLint publishes the schema lint report as a check.
+		CheckSchemaLint(context.Context, *TriggerContext, *SchemaLintReport) error
+	}
 	Logger interface {
 		// Infof logs an info message.
 		Infof(string, ...interface{})
@@ -280,6 +296,11 @@ func WithRuntimeAction() Option {
 		switch {
 		case c.action != nil:
 			// Do nothing. Action is already set.
+		case c.getenv("GITEA_ACTIONS") == "true" || c.getenv("FORGEJO_ACTIONS") == "true":
+			// Gitea and Forgejo set GITHUB_ACTIONS=true for compatibility,
+			// so they must be detected before GitHub Actions.
+			c.action = NewGitea(c.getenv, c.out)
+			c.atlas.SetStderr(logWriter(c.action.Warningf))
 		case c.getenv("GITHUB_ACTIONS") == "true":
 			c.action = NewGitHub(c.getenv, c.out)
 			// Forward all output from stderr to the action logger as warnings.
@@ -294,6 +315,20 @@ func WithRuntimeAction() Option {
 			c.action = NewAzure(c.getenv, c.out)
 		case c.getenv("TEAMCITY_VERSION") != "":
 			c.action = NewTeamCity(c.getenv, c.out)
+		case c.getenv("JENKINS_URL") != "" && c.getenv("BUILD_ID") != "":
+			c.action = NewJenkins(c.getenv, c.out)
+		case c.getenv("BUILDKITE") == "true":
+			c.action = NewBuildkite(c.getenv, c.out)
+		case c.getenv("CODEBUILD_BUILD_ID") != "":
+			c.action = NewCodeBuild(c.getenv, c.out)
+		case c.getenv("BUILD_ID") != "" && c.getenv("PROJECT_ID") != "":
+			c.action = NewCloudBuild(c.getenv, c.out)
+		case c.getenv("CI") == "woodpecker" || c.getenv("DRONE") == "true":
+			c.action = NewWoodpecker(c.getenv, c.out)
+		case c.getenv("CI") == "":
+			// Not running in a CI, e.g. on a developer machine. Unknown
+			// CI providers are reported by New, instead of running locally.
+			c.action = NewLocal(c.getenv, c.out)
 		}
 	}
 }
@@ -373,10 +408,17 @@ const (
 	CmdCopilot = "copilot"
 	// Misc Commands
 	CmdCloudRepoCreate = "create-repo"
+	// Pipeline Commands
+	CmdPipeline = "pipeline"
 )
 
 // Run runs the action based on the command name.
 func (a *Actions) Run(ctx context.Context, act string) error {
+	// Validate the inputs before running the action,
+	// to fail early on typos and invalid values.
+	if err := a.ValidateInputs(act); err != nil {
+		return err
+	}
 	// Set the working directory if provided.
 	if dir := a.WorkingDir(); dir != "" {
 		if err := os.Chdir(dir); err != nil {
@@ -430,6 +472,8 @@ func (a *Actions) Run(ctx context.Context, act string) error {
 		return a.Copilot(ctx)
 	case CmdCloudRepoCreate:
 		return a.CloudRepoCreate(ctx)
+	case CmdPipeline:
+		return a.Pipeline(ctx)
 	default:
 		return fmt.Errorf("unknown action: %s", act)
 	}
@@ -650,9 +694,18 @@ func (a *Actions) MigrateLint(ctx context.Context) error {
 	if payload.URL != "" {
 		a.SetOutput("report-url", payload.URL)
 	}
+	if name := a.GetInput("sarif-file"); name != "" {
+		dir := filepath.Join(a.GetInput("working-directory"), payload.Env.Dir)
+		if err := writeSARIF(name, lintSARIF(a.Version, dir, &payload)); err != nil {
+			return fmt.Errorf("failed to write SARIF file: %w", err)
+		}
+	}
 	if r, ok := a.Action.(Reporter); ok {
 		r.MigrateLint(ctx, &payload)
 	}
+	a.publishCheck(tc, func(c SCMChecker) error {
+		return c.CheckLint(ctx, tc, &payload)
+	})
 	if tc.PullRequest != nil {
 		// In case of a pull request, we need to add comments and suggestion to the PR.
 		c, err := tc.SCMClient()
@@ -682,6 +735,7 @@ func (a *Actions) MigrateTest(ctx context.Context) error {
 		RevisionsSchema: a.GetInput("revisions-schema"),
 		Paths:           a.GetArrayInput("paths"),
 	})
+	a.reportTests(ctx, CmdMigrateTest, result, err)
 	if err != nil {
 		return fmt.Errorf("`atlas migrate test` completed with errors:\n%s", err)
 	}
@@ -1058,18 +1112,16 @@ func (a *Actions) SchemaLint(ctx context.Context) error {
 		URL:              redactedURLs,
 		SchemaLintReport: report,
 	}
-	if len(report.Steps) == 0 {
-		if tc.PullRequest != nil {
-			c, err := tc.SCMClient()
-			if err != nil {
-				a.Errorf("failed to get SCM client: %v", err)
-			} else if err = c.CommentSchemaLint(ctx, tc, rp); err != nil {
-				a.Errorf("failed to comment on the pull request: %v", err)
-			}
+	if name := a.GetInput("sarif-file"); name != "" {
+		if err := writeSARIF(name, schemaLintSARIF(a.Version, rp)); err != nil {
+			return fmt.Errorf("failed to write SARIF file: %w", err)
 		}
-		a.Infof("`atlas schema lint` completed successfully, no issues found")
-		return nil
 	}
+	a.publishCheck(tc, func(c SCMChecker) error {
+		return c.CheckSchemaLint(ctx, tc, rp)
+	})
+	// Reporters are called also when no issues were found,
+	// so they can publish a passing report (e.g. Bitbucket).
 	if r, ok := a.Action.(Reporter); ok {
 		r.SchemaLint(ctx, rp)
 	}
@@ -1081,6 +1133,10 @@ func (a *Actions) SchemaLint(ctx context.Context) error {
 			a.Errorf("failed to comment on the pull request: %v", err)
 		}
 	}
+	if len(report.Steps) == 0 {
+		a.Infof("`atlas schema lint` completed successfully, no issues found")
+		return nil
+	}
 	errorCount, warningCount := 0, 0
 	for _, step := range report.Steps {
 		if step.Error {
@@ -1107,6 +1163,7 @@ func (a *Actions) SchemaTest(ctx context.Context) error {
 		URL:       a.GetInput("url"),
 		Vars:      a.GetVarsInput("vars"),
 	})
+	a.reportTests(ctx, CmdSchemaTest, result, err)
 	if err != nil {
 		return fmt.Errorf("`atlas schema test` completed with errors:\n%s", err)
 	}
@@ -1212,11 +1269,14 @@ func (a *Actions) SchemaPlan(ctx context.Context) error {
 	if r, ok := a.Action.(Reporter); ok {
 		r.SchemaPlan(ctx, plan)
 	}
-	c, err := tc.SCMClient()
-	if err != nil {
-		return err
-	}
+	a.publishCheck(tc, func(c SCMChecker) error {
+		return c.CheckPlan(ctx, tc, plan)
+	})
 	if tc.PullRequest != nil {
+		c, err := tc.SCMClient()
+		if err != nil {
+			return err
+		}
 		if err = c.CommentPlan(ctx, tc, plan); err != nil {
 			// Don't fail the action if the comment fails.
 			// It may be due to the missing permissions.
@@ -1527,6 +1587,7 @@ func (a *Actions) ScriptTest(ctx context.Context) error {
 		Run:       a.GetInput("run"),
 		Vars:      a.GetVarsInput("vars"),
 	})
+	a.reportTests(ctx, CmdScriptTest, result, err)
 	if err != nil {
 		return fmt.Errorf("`atlas script test` completed with errors:\n%s", err)
 	}
@@ -1814,10 +1875,11 @@ func (a *Actions) GetUin64Input(name string) uint64 {
 }
 
 // GetDurationInput returns the duration input with the given name.
-// The input should be a string representation of time.Duration. (e.g. "1s")
+// The input should be a string representation of time.Duration (e.g. "1s"),
+// or a number of seconds (e.g. "10").
 func (a *Actions) GetDurationInput(name string) time.Duration {
 	if s := strings.TrimSpace(a.GetInput(name)); s != "" {
-		v, err := time.ParseDuration(s)
+		v, err := parseDuration(s)
 		if err == nil {
 			return v
 		}
@@ -1827,6 +1889,15 @@ func (a *Actions) GetDurationInput(name string) time.Duration {
 	return 0
 }
 
+// parseDuration parses a time.Duration string, or a bare
+// non-negative integer that is interpreted as seconds.
+func parseDuration(s string) (time.Duration, error) {
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
+
 // GetAtlasURLInput returns the atlas URL input with the given name.
 // paramsName is List of input names to be added as query parameters.
 func (a *Actions) GetAtlasURLInput(name string, paramsName ...string) string {
@@ -2015,6 +2086,210 @@ func (tc *TriggerContext) GetRunContext() *atlasexec.RunContext {
 	return rc
 }
 
+// detectSCM detects the SCM provider of the repository. For self-managed instances, the provider
+// and its API URL can be set using the ATLAS_SCM_PROVIDER (github, gitlab or bitbucket) and
+// ATLAS_SCM_API_URL environment variables. Otherwise, the provider is detected by the hostname
+// of the repository URL, and for unknown hosts of pull requests, by probing the host API.
+// On success, it sets the SCMType and SCMClient of the trigger context, and the URL of the
+// pull request, if exists. If the provider is unknown, the SCMClient reports it when called.
+func (tc *TriggerContext) detectSCM(ctx context.Context) error {
+	u, err := url.Parse(tc.RepoURL)
+	if err != nil {
+		return fmt.Errorf("parsing repo URL %q: %w", tc.RepoURL, err)
+	}
+	tc.SCMClient = func() (SCMClient, error) {
+		return nil, errors.New("unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+	}
+	if u.Host == "" {
+		return nil
+	}
+	var (
+		act     = tc.Act
+		scm     = scmFromHost(u.Hostname())
+		managed = scm == "" // Self-managed instance.
+		origin  = webOrigin(u)
+		apiURL  = act.Getenv("ATLAS_SCM_API_URL")
+		repoURL = strings.TrimSuffix(tc.RepoURL, ".git")
+	)
+	switch p := act.Getenv("ATLAS_SCM_PROVIDER"); strings.ToLower(p) {
+	case "":
+		if managed && tc.PullRequest != nil {
+			scm = probeSCM(ctx, origin)
+		}
+	case "github":
+		scm = atlasexec.SCMTypeGithub
+	case "gitlab":
+		scm = atlasexec.SCMTypeGitlab
+	case "bitbucket":
+		scm = atlasexec.SCMTypeBitbucket
+	default:
+		return fmt.Errorf("unsupported ATLAS_SCM_PROVIDER %q, expected github, gitlab or bitbucket", p)
+	}
+	switch scm {
+	case atlasexec.SCMTypeGithub:
+		if apiURL == "" {
+			apiURL = act.Getenv("GITHUB_API_URL")
+		}
+		if apiURL == "" && managed {
+			// GitHub Enterprise Server.
+			apiURL = origin + "/api/v3"
+		}
+		tc.SCMType = atlasexec.SCMTypeGithub
+		tc.SCMClient = func() (SCMClient, error) {
+			token := act.Getenv("GITHUB_TOKEN")
+			if token == "" && !hasGitHubApp(act.Getenv) {
+				act.Warningf("GITHUB_TOKEN is not set, the action may not have all the permissions")
+			}
+			return newGitHubClient(act.Getenv, tc.Repo, apiURL, token)
+		}
+		if tc.PullRequest != nil {
+			tc.PullRequest.URL = fmt.Sprintf("%s/pull/%d", repoURL, tc.PullRequest.Number)
+		}
+	case atlasexec.SCMTypeGitlab:
+		if apiURL == "" {
+			apiURL = act.Getenv("CI_API_V4_URL")
+		}
+		if apiURL == "" && managed {
+			apiURL = origin + "/api/v4"
+		}
+		tc.SCMType = atlasexec.SCMTypeGitlab
+		tc.SCMClient = func() (SCMClient, error) {
+			token := act.Getenv("GITLAB_TOKEN")
+			if token == "" {
+				act.Warningf("GITLAB_TOKEN is not set, the action may not have all the permissions")
+			}
+			return NewGitLabClient(url.PathEscape(tc.Repo), apiURL, token)
+		}
+		if tc.PullRequest != nil {
+			tc.PullRequest.URL = fmt.Sprintf("%s/-/merge_requests/%d", repoURL, tc.PullRequest.Number)
+		}
+	case atlasexec.SCMTypeBitbucket:
+		tc.SCMType = atlasexec.SCMTypeBitbucket
+		tc.SCMClient = func() (SCMClient, error) {
+			if managed {
+				// The client implements the Bitbucket Cloud API, that Bitbucket Data Center does not serve.
+				return nil, errors.New("Bitbucket Data Center is not supported, only Bitbucket Cloud (bitbucket.org)")
+			}
+			token := act.Getenv("BITBUCKET_ACCESS_TOKEN")
+			if token == "" {
+				act.Warningf("BITBUCKET_ACCESS_TOKEN is not set, the action may not have all the permissions")
+			}
+			workspace, slug := act.Getenv("BITBUCKET_WORKSPACE"), act.Getenv("BITBUCKET_REPO_SLUG")
+			if workspace == "" || slug == "" {
+				workspace, slug, _ = strings.Cut(tc.Repo, "/")
+			}
+			return NewBitbucketClient(workspace, slug, apiURL, token)
+		}
+		if pr := tc.PullRequest; pr != nil {
+			pr.URL = fmt.Sprintf("%s/pull-requests/%d", repoURL, pr.Number)
+			// Bitbucket Data Center repositories are cloned from /scm/{project}/{repo}.
+			if p, r, ok := strings.Cut(strings.TrimPrefix(strings.TrimSuffix(u.Path, ".git"), "/scm/"), "/"); managed && ok {
+				pr.URL = fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%d", origin, strings.ToUpper(p), r, pr.Number)
+			}
+		}
+	}
+	return nil
+}
+
+// scmFromHost returns the SCM provider of the known hosted services.
+func scmFromHost(host string) atlasexec.SCMType {
+	switch h := strings.ToLower(host); {
+	case h == "github.com" || strings.HasSuffix(h, ".github.com"):
+		return atlasexec.SCMTypeGithub
+	case h == "gitlab.com" || strings.HasSuffix(h, ".gitlab.com"):
+		return atlasexec.SCMTypeGitlab
+	case h == "bitbucket.org":
+		return atlasexec.SCMTypeBitbucket
+	}
+	return ""
+}
+
+// webOrigin returns the origin of the web server of the given repository URL.
+// Repositories cloned over SSH are expected to be served over HTTPS.
+func webOrigin(u *url.URL) string {
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return u.Scheme + "://" + u.Host
+	}
+	return "https://" + u.Hostname()
+}
+
+// probeSCM detects the provider of a self-managed SCM instance by calling
+// the well-known API endpoints of each provider. It returns an empty string
+// if none of them responded. The host is not trusted yet, so the requests
+// are sent without credentials and redirects to other hosts are not followed.
+func probeSCM(ctx context.Context, origin string) atlasexec.SCMType {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Host != via[0].URL.Host {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+	for _, p := range []struct {
+		path  string
+		scm   atlasexec.SCMType
+		match func(*http.Response) bool
+	}{
+		{
+			// GitLab responds with 401 to unauthenticated requests,
+			// but its API responses carry the X-Gitlab-Meta header.
+			path: "/api/v4/version",
+			scm:  atlasexec.SCMTypeGitlab,
+			match: func(r *http.Response) bool {
+				return r.Header.Get("X-Gitlab-Meta") != "" || r.StatusCode == http.StatusOK && jsonHas(r, "version")
+			},
+		},
+		{
+			path: "/api/v3/meta",
+			scm:  atlasexec.SCMTypeGithub,
+			match: func(r *http.Response) bool {
+				return r.Header.Get("X-GitHub-Enterprise-Version") != "" || r.StatusCode == http.StatusOK && jsonHas(r, "installed_version")
+			},
+		},
+		{
+			path: "/rest/api/1.0/application-properties",
+			scm:  atlasexec.SCMTypeBitbucket,
+			match: func(r *http.Response) bool {
+				var props struct {
+					DisplayName string `json:"displayName"`
+				}
+				return r.StatusCode == http.StatusOK && json.NewDecoder(r.Body).Decode(&props) == nil && props.DisplayName == "Bitbucket"
+			},
+		},
+	} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+p.path, nil)
+		if err != nil {
+			return ""
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ""
+			}
+			continue
+		}
+		ok := p.match(res)
+		res.Body.Close()
+		if ok {
+			return p.scm
+		}
+	}
+	return ""
+}
+
+// jsonHas reports if the response body is a JSON object with the given key.
+func jsonHas(r *http.Response, key string) bool {
+	var m map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		return false
+	}
+	_, ok := m[key]
+	return ok
+}
+
 // newFiles returns the files that only exists in the current hash.
 func newFiles(base, current migrate.HashFile) []string {
 	m := maps.Collect(hashIter(current))
@@ -2064,6 +2339,45 @@ func stepIsError(s *atlasexec.StepReport) bool {
 	return s.Error != "" || (s.Result != nil && s.Result.Error != "")
 }
 
+// publishCheck publishes a check on the pull request commit, if the SCM client supports it.
+// On GitHub Actions, the results are already reported as annotations by the workflow commands.
+// Elsewhere, checks are published only when the GitHub App credentials are set, as the Check
+// Runs API is not available to personal access tokens.
+func (a *Actions) publishCheck(tc *TriggerContext, fn func(SCMChecker) error) {
+	if tc.PullRequest == nil || tc.SCMClient == nil || tc.SCMType != atlasexec.SCMTypeGithub ||
+		a.GetType() == atlasexec.TriggerTypeGithubAction || !hasGitHubApp(a.Getenv) {
+		return
+	}
+	c, err := tc.SCMClient()
+	if err != nil {
+		a.Warningf("failed to get SCM client: %v", err)
+		return
+	}
+	if ch, ok := c.(SCMChecker); ok {
+		// Don't fail the action if the check fails.
+		// It may be due to the missing permissions.
+		if err := fn(ch); err != nil {
+			a.Warningf("failed to publish the check: %v", err)
+		}
+	}
+}
+
+// diagnosticLine returns the 1-based line number of the given position in the file.
+func diagnosticLine(f *atlasexec.FileReport, pos int) int {
+	if pos <= 0 || pos > len(f.Text) {
+		return 1
+	}
+	return strings.Count(f.Text[:pos], "\n") + 1
+}
+
+// diagnosticText returns the text of the diagnostic, with its code if exists.
+func diagnosticText(text, code string) string {
+	if code == "" {
+		return text
+	}
+	return fmt.Sprintf("%s (%s)", text, code)
+}
+
 var (
 	//go:embed comments
 	comments embed.FS
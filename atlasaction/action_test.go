@@ -72,6 +72,21 @@ func TestCopilot(t *testing.T) {
 		m := slices.Collect(maps.Keys(scm.comments))[0]
 		require.Contains(t, m, p)
 	})
+	t.Run("short comment", func(t *testing.T) {
+		for _, body := range []string{"+1", "LGTM", ""} {
+			scm := &mockSCM{}
+			err := (&atlasaction.Actions{
+				Action: &mockAction{
+					trigger: &atlasaction.TriggerContext{
+						SCMClient: func() (atlasaction.SCMClient, error) { return scm, nil },
+						Comment:   &atlasaction.Comment{Body: body},
+					},
+				}, Atlas: fn("", "", ""),
+			}).Copilot(context.Background())
+			require.NoError(t, err)
+			require.Empty(t, scm.comments)
+		}
+	})
 }
 
 func TestMigrateApply(t *testing.T) {
@@ -1955,13 +1970,16 @@ func TestMigrateLint(t *testing.T) {
 				_, err = writer.Write(b)
 				require.NoError(t, err)
 				return
-			// Create comment endpoint
-			case path == "/repos/test-owner/test-repository/pulls/0/comments" && method == http.MethodPost:
-				var payload map[string]any
+			// Create review endpoint
+			case path == "/repos/test-owner/test-repository/pulls/0/reviews" && method == http.MethodPost:
+				var payload struct {
+					Comments []map[string]any `json:"comments"`
+				}
 				require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
-				payload["id"] = 123
-				comments = append(comments, payload)
-				writer.WriteHeader(http.StatusCreated)
+				for _, c := range payload.Comments {
+					c["id"] = 123
+					comments = append(comments, c)
+				}
 				return
 			// Update comment endpoint
 			case path == "/repos/test-owner/test-repository/pulls/comments/123" && method == http.MethodPatch:
@@ -2015,7 +2033,8 @@ func TestMigrateLint(t *testing.T) {
 			"Ensure to run `atlas migrate hash --dir \"file://testdata/migrations_destructive\"` after applying the suggested changes.\n"+
 			"<!-- generated by ariga/atlas-action for Add a pre-migration check to ensure table \"t1\" is empty before dropping it -->", comments[0]["body"])
 		require.Equal(t, float64(1), comments[0]["line"])
-		// Run Lint against a directory that has an existing suggestion comment, expecting a PATCH of the comment
+		// Run Lint against a directory that has an outdated suggestion comment, expecting a PATCH of the comment
+		comments[0]["body"] = strings.Replace(comments[0]["body"].(string), "Add a pre-migration check", "Add a check", 1)
 		err = tt.newActs(t).MigrateLint(context.Background())
 		require.ErrorContains(t, err, "https://migration-lint-report-url")
 		require.Len(t, comments, 1)
@@ -2037,13 +2056,16 @@ func TestMigrateLint(t *testing.T) {
 				_, err = writer.Write(b)
 				require.NoError(t, err)
 				return
-			// Create comment endpoint
-			case path == "/repos/test-owner/test-repository/pulls/0/comments" && method == http.MethodPost:
-				var payload map[string]any
+			// Create review endpoint
+			case path == "/repos/test-owner/test-repository/pulls/0/reviews" && method == http.MethodPost:
+				var payload struct {
+					Comments []map[string]any `json:"comments"`
+				}
 				require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
-				payload["id"] = 123
-				comments = append(comments, payload)
-				writer.WriteHeader(http.StatusCreated)
+				for _, c := range payload.Comments {
+					c["id"] = 123
+					comments = append(comments, c)
+				}
 				return
 			// Update comment endpoint
 			case path == "/repos/test-owner/test-repository/pulls/comments/123" && method == http.MethodPatch:
@@ -2144,13 +2166,16 @@ func TestMigrateLint(t *testing.T) {
 				_, err = writer.Write(b)
 				require.NoError(t, err)
 				return
-			// Create comment endpoint
-			case path == "/repos/test-owner/test-repository/pulls/0/comments" && method == http.MethodPost:
-				var payload map[string]any
+			// Create review endpoint
+			case path == "/repos/test-owner/test-repository/pulls/0/reviews" && method == http.MethodPost:
+				var payload struct {
+					Comments []map[string]any `json:"comments"`
+				}
 				require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
-				payload["id"] = 123
-				comments = append(comments, payload)
-				writer.WriteHeader(http.StatusCreated)
+				for _, c := range payload.Comments {
+					c["id"] = 123
+					comments = append(comments, c)
+				}
 				return
 			// Update comment endpoint
 			case path == "/repos/test-owner/test-repository/pulls/comments/123" && method == http.MethodPatch:
@@ -2204,7 +2229,8 @@ func TestMigrateLint(t *testing.T) {
 			"Ensure to run `atlas migrate hash --dir \"file://migrations_destructive\"` after applying the suggested changes.\n"+
 			"<!-- generated by ariga/atlas-action for Add a pre-migration check to ensure table \"t1\" is empty before dropping it -->", comments[0]["body"])
 		require.Equal(t, float64(1), comments[0]["line"])
-		// Run Lint against a directory that has an existing suggestion comment, expecting a PATCH of the comment
+		// Run Lint against a directory that has an outdated suggestion comment, expecting a PATCH of the comment
+		comments[0]["body"] = strings.Replace(comments[0]["body"].(string), "Add a pre-migration check", "Add a check", 1)
 		err = tt.newActs(t).MigrateLint(context.Background())
 		require.ErrorContains(t, err, "https://migration-lint-report-url")
 		require.Len(t, comments, 1)
@@ -2309,13 +2335,16 @@ func TestMigrateLint(t *testing.T) {
 				_, err = writer.Write(b)
 				require.NoError(t, err)
 				return
-			// Create comment endpoint
-			case path == "/repos/test-owner/test-repository/pulls/0/comments" && method == http.MethodPost:
-				var payload map[string]any
+			// Create review endpoint
+			case path == "/repos/test-owner/test-repository/pulls/0/reviews" && method == http.MethodPost:
+				var payload struct {
+					Comments []map[string]any `json:"comments"`
+				}
 				require.NoError(t, json.NewDecoder(request.Body).Decode(&payload))
-				payload["id"] = 123
-				comments = append(comments, payload)
-				writer.WriteHeader(http.StatusCreated)
+				for _, c := range payload.Comments {
+					c["id"] = 123
+					comments = append(comments, c)
+				}
 				return
 			// List pull request files endpoint
 			case path == "/repos/test-owner/test-repository/pulls/0/files" && method == http.MethodGet:
@@ -2421,9 +2450,8 @@ func TestMigrateLint(t *testing.T) {
 			case path == "/repos/test-owner/test-repository/pulls/42/comments" && method == http.MethodGet:
 				_, err := writer.Write([]byte(`[]`))
 				require.NoError(t, err)
-			// Create pull request comment endpoint
-			case path == "/repos/test-owner/test-repository/pulls/42/comments" && method == http.MethodPost:
-				writer.WriteHeader(http.StatusCreated)
+			// Create pull request review endpoint
+			case path == "/repos/test-owner/test-repository/pulls/42/reviews" && method == http.MethodPost:
 			// List pull request files endpoint
 			case path == "/repos/test-owner/test-repository/pulls/42/files" && method == http.MethodGet:
 				_, err := writer.Write([]byte(`[{"filename": "testdata/migrations_destructive/20230925192914.sql"}]`))
@@ -2445,7 +2473,7 @@ func TestMigrateLint(t *testing.T) {
 		// Run Lint while expecting no errors
 		err := tt.newActs(t).MigrateLint(context.Background())
 		require.NoError(t, err)
-		require.Equal(t, 3, len(ghPayloads))
+		require.Equal(t, 4, len(ghPayloads))
 		found := slices.IndexFunc(ghPayloads, func(gh ghPayload) bool {
 			if gh.Method != http.MethodPost {
 				return false
@@ -2460,7 +2488,7 @@ func TestMigrateLint(t *testing.T) {
 		tt.setInput("dir", "file://testdata/migrations_destructive")
 		err = tt.newActs(t).MigrateLint(context.Background())
 		require.ErrorContains(t, err, "https://migration-lint-report-url")
-		require.Equal(t, 8, len(ghPayloads))
+		require.Equal(t, 9, len(ghPayloads))
 		found = slices.IndexFunc(ghPayloads, func(gh ghPayload) bool {
 			if gh.Method != http.MethodPost {
 				return false
@@ -2475,7 +2503,7 @@ func TestMigrateLint(t *testing.T) {
 		tt.setInput("dir-name", "other-dir-slug")
 		err = tt.newActs(t).MigrateLint(context.Background())
 		require.ErrorContains(t, err, "https://migration-lint-report-url")
-		require.Equal(t, 13, len(ghPayloads))
+		require.Equal(t, 14, len(ghPayloads))
 		found = slices.IndexFunc(ghPayloads, func(gh ghPayload) bool {
 			if gh.Method != http.MethodPatch {
 				return false
@@ -2489,7 +2517,7 @@ func TestMigrateLint(t *testing.T) {
 		// Run Lint with input errors, no calls to github api should be made
 		tt.setInput("dir-name", "fake-dir-name")
 		err = tt.newActs(t).MigrateLint(context.Background())
-		require.Equal(t, 13, len(ghPayloads))
+		require.Equal(t, 14, len(ghPayloads))
 		require.ErrorContains(t, err, `dir "fake-dir-name" not found`)
 	})
 }
@@ -3567,7 +3595,7 @@ func TestSchemaLint(t *testing.T) {
 		require.NoError(t, err)
 		err = a.SchemaLint(context.Background())
 		require.NoError(t, err)
-		require.Equal(t, 0, act.summary)
+		require.Equal(t, 1, act.summary)
 	})
 	t.Run("lint - with warning issues", func(t *testing.T) {
 		m := &mockAtlas{}
@@ -10,15 +10,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 
 	"ariga.io/atlas-action/internal/azuredevops"
 	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/sqlcheck"
 	"github.com/fatih/color"
 	"golang.org/x/oauth2"
 )
@@ -34,7 +38,10 @@ type (
 	}
 )
 
-var _ Action = (*Azure)(nil)
+var (
+	_ Action   = (*Azure)(nil)
+	_ Reporter = (*Azure)(nil)
+)
 
 // NewAzure returns a new Action for Azure DevOps.
 func NewAzure(getenv func(string) string, w io.Writer) *Azure {
@@ -104,6 +111,9 @@ func (a *Azure) GetTriggerContext(context.Context) (_ *TriggerContext, err error
 			}
 		}
 		tc.SCMClient = func() (SCMClient, error) {
+			if hasGitHubApp(a.getenv) {
+				return newGitHubClient(a.getenv, tc.Repo, a.getenv("GITHUB_API_URL"), "")
+			}
 			var token string
 			if c := a.GetInput("githubConnection"); c != "" {
 				token, err = a.getGHToken(c)
@@ -202,6 +212,160 @@ func (a *Azure) getEndpointAuthorization(id string) (*azureEndpointAuthorization
 	return &auth, nil
 }
 
+// MigrateApply implements Reporter.
+func (a *Azure) MigrateApply(_ context.Context, r *atlasexec.MigrateApply) {
+	a.uploadSummary("migrate-apply", r)
+	if r.Error != "" {
+		a.complete("Failed", r.Error)
+	}
+}
+
+// MigrateLint implements Reporter.
+func (a *Azure) MigrateLint(_ context.Context, r *atlasexec.SummaryReport) {
+	errs, warns := a.logLintIssues(r)
+	a.uploadSummary("migrate-lint", r)
+	a.completeLint(errs, warns)
+}
+
+// SchemaApply implements Reporter.
+func (a *Azure) SchemaApply(_ context.Context, r *atlasexec.SchemaApply) {
+	a.uploadSummary("schema-apply", r)
+	if r.Error != "" {
+		a.complete("Failed", r.Error)
+	}
+}
+
+// SchemaPlan implements Reporter.
+func (a *Azure) SchemaPlan(_ context.Context, r *atlasexec.SchemaPlan) {
+	var errs, warns int
+	if r.Lint != nil {
+		errs, warns = a.logLintIssues(r.Lint)
+	}
+	a.uploadSummary("schema-plan", map[string]any{"Plan": r})
+	a.completeLint(errs, warns)
+}
+
+// SchemaLint implements Reporter.
+func (a *Azure) SchemaLint(_ context.Context, r *SchemaLintReport) {
+	var errs, warns int
+	for _, s := range r.Steps {
+		for _, d := range s.Diagnostics {
+			props := map[string]string{"type": "warning"}
+			if s.Error {
+				props["type"] = "error"
+				errs++
+			} else {
+				warns++
+			}
+			if d.Code != "" {
+				props["code"] = d.Code
+			}
+			if d.Pos != nil {
+				file := d.Pos.Filename
+				if !filepath.IsAbs(file) {
+					// If the file is not absolute, we assume it is relative to the working directory.
+					file = filepath.Join(a.GetInput("working-directory"), file)
+				}
+				props["sourcepath"] = file
+				props["linenumber"] = strconv.Itoa(max(1, d.Pos.Start.Line))
+			}
+			a.command("task.logissue", fmt.Sprintf("%s: %s", s.Text, diagnosticText(d.Text, d.Code)), props)
+		}
+	}
+	a.uploadSummary("schema-lint", r)
+	a.completeLint(errs, warns)
+}
+
+// logLintIssues logs the lint diagnostics as issues, attached to the
+// files and lines they were reported on. It returns the number of
+// errors and warnings that were logged.
+func (a *Azure) logLintIssues(r *atlasexec.SummaryReport) (errs, warns int) {
+	dir := filepath.Join(a.GetInput("working-directory"), r.Env.Dir)
+	for _, f := range r.Files {
+		path := filepath.Join(dir, f.Name)
+		if f.Error != "" && len(f.Reports) == 0 {
+			a.command("task.logissue", f.Error, map[string]string{
+				"type":       "error",
+				"sourcepath": path,
+				"linenumber": "1",
+			})
+			errs++
+			continue
+		}
+		for _, rr := range f.Reports {
+			for _, d := range rr.Diagnostics {
+				props := map[string]string{
+					"type":       "warning",
+					"sourcepath": path,
+					"linenumber": strconv.Itoa(diagnosticLine(f, d.Pos)),
+				}
+				if f.Error != "" {
+					props["type"] = "error"
+					errs++
+				} else {
+					warns++
+				}
+				if d.Code != "" {
+					props["code"] = d.Code
+				}
+				a.command("task.logissue", fmt.Sprintf("%s: %s", rr.Text, diagnosticText(d.Text, d.Code)), props)
+			}
+		}
+	}
+	return errs, warns
+}
+
+// completeLint sets the result of the task based on the lint issues.
+func (a *Azure) completeLint(errs, warns int) {
+	switch {
+	case errs > 0:
+		a.complete("Failed", fmt.Sprintf("Atlas lint found %d errors", errs))
+	case warns > 0:
+		a.complete("SucceededWithIssues", fmt.Sprintf("Atlas lint found %d warnings", warns))
+	}
+}
+
+// complete sets the result of the current task.
+// https://learn.microsoft.com/en-us/azure/devops/pipelines/scripts/logging-commands#complete-finish-timeline
+func (a *Azure) complete(result, message string) {
+	a.command("task.complete", message, map[string]string{
+		"result": result,
+	})
+}
+
+// uploadSummary renders the given template and attaches it to the
+// Extensions tab of the pipeline run, using task.uploadsummary.
+// https://learn.microsoft.com/en-us/azure/devops/pipelines/scripts/logging-commands#uploadsummary-add-some-markdown-content-to-the-build-summary
+func (a *Azure) uploadSummary(name string, data any) {
+	summary, err := RenderTemplate(name+".tmpl", data, nil)
+	if err != nil {
+		a.Errorf("failed to create summary: %v", err)
+		return
+	}
+	dir := a.getVar("Agent.TempDirectory")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		a.Errorf("failed to create summary directory: %v", err)
+		return
+	}
+	// The summary is uploaded by the agent after the step ends,
+	// so each summary is written to its own file.
+	path := filepath.Join(dir, fmt.Sprintf("atlas-%s.md", name))
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("atlas-%s-%d.md", name, i))
+	}
+	if err := os.WriteFile(path, []byte(summary), 0644); err != nil {
+		a.Errorf("failed to write summary: %v", err)
+		return
+	}
+	a.command("task.uploadsummary", path, nil)
+}
+
 func (a *Azure) getVar(name string) string {
 	return a.getenv(strings.ToUpper(strings.ReplaceAll(name, ".", "_")))
 }
@@ -281,24 +445,48 @@ func (c *AzureDevOpsClient) PullRequest(ctx context.Context, number int) (*PullR
 	if err != nil {
 		return nil, err
 	}
-	return &PullRequest{
-		Number: pr.ID,
-	}, nil
+	return convertAzurePullRequest(pr), nil
 }
 
 // CreatePullRequest implements SCMClient.
-func (c *AzureDevOpsClient) CreatePullRequest(_ context.Context, _, _, _, _ string) (*PullRequest, error) {
-	panic("unimplemented: CreatePullRequest for AzureDevOpsClient")
+func (c *AzureDevOpsClient) CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	pr, err := c.Client.CreatePullRequest(ctx, head, base, title, body)
+	if err != nil {
+		return nil, err
+	}
+	return convertAzurePullRequest(pr), nil
 }
 
 // CopilotSession implements SCMClient.
-func (c *AzureDevOpsClient) CopilotSession(context.Context, *TriggerContext) (string, error) {
-	panic("unimplemented: CopilotSession for AzureDevOpsClient")
+func (c *AzureDevOpsClient) CopilotSession(ctx context.Context, tc *TriggerContext) (string, error) {
+	threads, err := c.ListCommentThreads(ctx, func() int {
+		if tc.PullRequest != nil {
+			return tc.PullRequest.Number
+		}
+		return tc.Comment.Number
+	}())
+	if err != nil {
+		return "", fmt.Errorf("failed to list comment threads: %w", err)
+	}
+	for _, thread := range threads {
+		for _, c := range thread.Comments {
+			if m := reCopilotSession.FindStringSubmatch(c.Content); len(m) > 1 {
+				return m[1], nil
+			}
+		}
+	}
+	return "", nil
 }
 
 // CommentCopilot implements SCMClient.
-func (c *AzureDevOpsClient) CommentCopilot(context.Context, int, *Copilot) error {
-	panic("unimplemented: CommentCopilot for AzureDevOpsClient")
+func (c *AzureDevOpsClient) CommentCopilot(ctx context.Context, pr int, cp *Copilot) error {
+	var buf strings.Builder
+	if cp.Prompt != "" {
+		fmt.Fprintf(&buf, "> %s\n\n", cp.Prompt)
+	}
+	fmt.Fprintf(&buf, copilotSession, cp.Response, cp.Session)
+	_, err := c.AddComment(ctx, pr, buf.String())
+	return err
 }
 
 // CommentLint implements SCMClient.
@@ -307,7 +495,14 @@ func (c *AzureDevOpsClient) CommentLint(ctx context.Context, tc *TriggerContext,
 	if err != nil {
 		return err
 	}
-	return c.upsertComment(ctx, tc.PullRequest, tc.Act.GetInput("dir-name"), comment)
+	err = c.upsertComment(ctx, tc.PullRequest, tc.Act.GetInput("dir-name"), comment)
+	if err != nil {
+		return err
+	}
+	if err = c.upsertFileThreads(ctx, tc.PullRequest, tc.Act.GetInput("working-directory"), r); err != nil {
+		tc.Act.Errorf("failed to add file comments on the pull request: %v", err)
+	}
+	return nil
 }
 
 // CommentPlan implements SCMClient.
@@ -386,4 +581,188 @@ func (c *AzureDevOpsClient) comment(ctx context.Context, pr *PullRequest, id, co
 	return err
 }
 
+// azureFileThread is a comment thread on a line of a migration file.
+type azureFileThread struct {
+	ID      string // Thread ID, used in the comment marker.
+	Path    string // File path, relative to the repository root.
+	Line    int
+	EndLine int
+	Content string
+}
+
+// azureLintThreadPrefix prefixes the IDs of the file threads created for lint findings.
+const azureLintThreadPrefix = "lint-"
+
+var reAzureLintMarker = regexp.MustCompile(`<!-- generated by ariga/atlas-action for ` + azureLintThreadPrefix + `\S+ -->`)
+
+// upsertFileThreads upserts a comment thread on the migration file line of each lint finding,
+// and resolves the threads of findings that no longer exist in the linted directory.
+func (c *AzureDevOpsClient) upsertFileThreads(ctx context.Context, pr *PullRequest, cw string, r *atlasexec.SummaryReport) error {
+	ts, err := azureLintThreads(cw, r)
+	if err != nil {
+		return err
+	}
+	threads, err := c.ListCommentThreads(ctx, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to list comment threads: %w", err)
+	}
+	var (
+		iteration int
+		changes   []azuredevops.IterationChange
+	)
+	markers := make(map[string]bool, len(ts))
+	for _, t := range ts {
+		marker := commentMarker(t.ID)
+		markers[marker] = true
+		content := t.Content + "\n" + marker
+		// Update the existing thread, and reopen it if the finding was resolved by
+		// a previous run. Statuses set by reviewers (e.g. "wontFix") are kept.
+		if i := slices.IndexFunc(threads, func(th azuredevops.CommentThread) bool {
+			return len(th.Comments) > 0 && strings.Contains(th.Comments[0].Content, marker)
+		}); i != -1 {
+			th := threads[i]
+			if th.Comments[0].Content != content {
+				if _, err := c.UpdateComment(ctx, pr.Number, th.ID, th.Comments[0].ID, content); err != nil {
+					return err
+				}
+			}
+			if th.Status == azuredevops.ThreadStatusFixed {
+				if err := c.UpdateThreadStatus(ctx, pr.Number, th.ID, azuredevops.ThreadStatusActive); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		// The changes are needed only for creating new threads.
+		if iteration == 0 {
+			if iteration, err = c.LatestIteration(ctx, pr.Number); err != nil {
+				return err
+			}
+			if changes, err = c.IterationChanges(ctx, pr.Number, iteration); err != nil {
+				return err
+			}
+		}
+		// Comment only on files that are part of the pull request.
+		ch := slices.IndexFunc(changes, func(ch azuredevops.IterationChange) bool {
+			return ch.Item.Path == t.Path
+		})
+		if ch == -1 {
+			continue
+		}
+		_, err := c.CreateThread(ctx, pr.Number, &azuredevops.CreateCommentThreadRequest{
+			Comments: []azuredevops.CreateCommentRequest{{Content: content}},
+			Status:   azuredevops.ThreadStatusActive,
+			ThreadContext: &azuredevops.ThreadContext{
+				FilePath:       t.Path,
+				RightFileStart: &azuredevops.FilePosition{Line: t.Line, Offset: 1},
+				RightFileEnd:   &azuredevops.FilePosition{Line: t.EndLine, Offset: 1},
+			},
+			PullRequestThreadContext: &azuredevops.PullRequestThreadContext{
+				ChangeTrackingID: changes[ch].ChangeTrackingID,
+				IterationContext: azuredevops.IterationContext{
+					FirstComparingIteration:  1,
+					SecondComparingIteration: iteration,
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	dir := azurePath(filepath.Join(cw, r.Env.Dir)) + "/"
+	for _, th := range threads {
+		if th.Status != azuredevops.ThreadStatusActive || th.ThreadContext == nil || len(th.Comments) == 0 ||
+			!strings.HasPrefix(th.ThreadContext.FilePath, dir) {
+			continue
+		}
+		if m := reAzureLintMarker.FindString(th.Comments[0].Content); m != "" && !markers[m] {
+			if err := c.UpdateThreadStatus(ctx, pr.Number, th.ID, azuredevops.ThreadStatusFixed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// azureLintThreads returns the file threads for the findings of the lint report.
+func azureLintThreads(cw string, r *atlasexec.SummaryReport) ([]*azureFileThread, error) {
+	var ts []*azureFileThread
+	add := func(path string, line, end int, content string, parts ...string) error {
+		id, err := hash(append([]string{path}, parts...)...)
+		if err != nil {
+			return err
+		}
+		ts = append(ts, &azureFileThread{
+			ID:      azureLintThreadPrefix + id,
+			Path:    path,
+			Line:    line,
+			EndLine: max(line, end),
+			Content: content,
+		})
+		return nil
+	}
+	for _, f := range r.Files {
+		path := azurePath(filepath.Join(cw, r.Env.Dir, f.Name))
+		if f.Error != "" && len(f.Reports) == 0 {
+			if err := add(path, 1, 1, fmt.Sprintf("**%s**", f.Error), f.Error); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, rr := range f.Reports {
+			for _, fix := range rr.SuggestedFixes {
+				if fix.TextEdit == nil {
+					continue
+				}
+				content := fmt.Sprintf("**%s**\n\n%s", rr.Text, azureSuggestedFix(fix))
+				if err := add(path, fix.TextEdit.Line, fix.TextEdit.End, content, rr.Text, fix.Message); err != nil {
+					return nil, err
+				}
+			}
+			for _, d := range rr.Diagnostics {
+				var b strings.Builder
+				fmt.Fprintf(&b, "**%s**\n\n%s", rr.Text, d.Text)
+				if d.Code != "" {
+					fmt.Fprintf(&b, " [%s](https://atlasgo.io/lint/analyzers#%s)", d.Code, d.Code)
+				}
+				for _, fix := range d.SuggestedFixes {
+					if fix.TextEdit != nil {
+						fmt.Fprintf(&b, "\n\n%s", azureSuggestedFix(fix))
+					}
+				}
+				line := diagnosticLine(f, d.Pos)
+				if err := add(path, line, line, b.String(), rr.Text, d.Code, d.Text); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return ts, nil
+}
+
+// azureSuggestedFix renders the suggested fix as a code block, as
+// Azure DevOps does not support applying suggestions from comments.
+func azureSuggestedFix(f sqlcheck.SuggestedFix) string {
+	return fmt.Sprintf("%s\n```sql\n%s\n```", f.Message, f.TextEdit.NewText)
+}
+
+// azurePath returns the path in the format used by Azure Repos, e.g. "/migrations/1.sql".
+func azurePath(p string) string {
+	return "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(p)), "/")
+}
+
+// convertAzurePullRequest converts an Azure DevOps pull request to a PullRequest.
+func convertAzurePullRequest(pr *azuredevops.PullRequest) *PullRequest {
+	if pr == nil {
+		return nil
+	}
+	return &PullRequest{
+		Number: pr.ID,
+		URL:    pr.URL(),
+		Body:   pr.Description,
+		Commit: pr.LastMergeSourceCommit.CommitID,
+		Ref:    pr.SourceBranch(),
+	}
+}
+
 var _ SCMClient = (*AzureDevOpsClient)(nil)
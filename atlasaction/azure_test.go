@@ -16,7 +16,10 @@ import (
 	"strings"
 	"testing"
 
+	"ariga.io/atlas-action/internal/azuredevops"
 	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
 	"github.com/rogpeppe/go-internal/diff"
 	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/stretchr/testify/require"
@@ -38,6 +41,7 @@ func TestAzureDevOpsTask(t *testing.T) {
 			e.Setenv("TF_BUILD", "True")
 			e.Setenv("BUILD_REPOSITORY_PROVIDER", "GitHub")
 			e.Setenv("BUILD_SOURCESDIRECTORY", e.WorkDir)
+			e.Setenv("AGENT_TEMPDIRECTORY", filepath.Join(e.WorkDir, "tmp"))
 			// Set up mock Azure DevOps API URL
 			e.Setenv("AZURE_DEVOPS_API_URL", srv.URL)
 			return nil
@@ -177,6 +181,122 @@ func TestAzureDevOpsClient(t *testing.T) {
 		// Test that the client implements SCMClient interface
 		var _ SCMClient = client
 	})
+
+	t.Run("Copilot", func(t *testing.T) {
+		dir := t.TempDir()
+		srv := httptest.NewServer(mockAzureDevOpsHandler(dir, "test-token"))
+		defer srv.Close()
+		t.Setenv("AZURE_DEVOPS_API_URL", srv.URL)
+		client, err := NewAzureDevOpsClient("testorg", "testproject", "testrepo", "test-token")
+		require.NoError(t, err)
+		tc := &TriggerContext{PullRequest: &PullRequest{Number: 1}}
+		s, err := client.CopilotSession(context.Background(), tc)
+		require.NoError(t, err)
+		require.Empty(t, s)
+		require.NoError(t, client.CommentCopilot(context.Background(), 1, &Copilot{
+			Session:  "s1",
+			Prompt:   "Generate missing test cases for my schema.",
+			Response: "Done.",
+		}))
+		s, err = client.CopilotSession(context.Background(), tc)
+		require.NoError(t, err)
+		require.Equal(t, "s1", s)
+	})
+}
+
+func TestAzureDevOpsClient_FileThreads(t *testing.T) {
+	var (
+		threads  []*azuredevops.CommentThread
+		statuses = map[string]string{}
+	)
+	m := http.NewServeMux()
+	m.HandleFunc("GET /org/project/_apis/git/repositories/repo/pullrequests/1/threads", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"value": threads}))
+	})
+	m.HandleFunc("POST /org/project/_apis/git/repositories/repo/pullrequests/1/threads", func(w http.ResponseWriter, r *http.Request) {
+		var req azuredevops.CreateCommentThreadRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, 3, req.PullRequestThreadContext.IterationContext.SecondComparingIteration)
+		require.Equal(t, 9, req.PullRequestThreadContext.ChangeTrackingID)
+		threads = append(threads, &azuredevops.CommentThread{
+			ID:            len(threads) + 1,
+			Status:        req.Status,
+			ThreadContext: req.ThreadContext,
+			Comments:      []azuredevops.Comment{{ID: 1, Content: req.Comments[0].Content}},
+		})
+		w.WriteHeader(http.StatusCreated)
+		require.NoError(t, json.NewEncoder(w).Encode(threads[len(threads)-1]))
+	})
+	m.HandleFunc("PATCH /org/project/_apis/git/repositories/repo/pullrequests/1/threads/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Status string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		statuses[r.PathValue("id")] = body.Status
+		w.Write([]byte(`{}`))
+	})
+	m.HandleFunc("GET /org/project/_apis/git/repositories/repo/pullrequests/1/iterations", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":[{"id":1},{"id":3},{"id":2}]}`))
+	})
+	m.HandleFunc("GET /org/project/_apis/git/repositories/repo/pullrequests/1/iterations/3/changes", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"changeEntries":[{"changeTrackingId":9,"item":{"path":"/db/migrations/1.sql"}}]}`))
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	t.Setenv("AZURE_DEVOPS_API_URL", srv.URL)
+	c, err := NewAzureDevOpsClient("org", "project", "repo", "")
+	require.NoError(t, err)
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{{
+			Name: "1.sql",
+			Text: "CREATE TABLE t(c int);\nDROP TABLE t;\n",
+			Reports: []sqlcheck.Report{{
+				Text: "destructive changes detected",
+				Diagnostics: []sqlcheck.Diagnostic{
+					{Pos: 23, Text: `Dropping table "t"`, Code: "DS102"},
+				},
+			}},
+			Error: "destructive changes detected",
+		}},
+	}
+	lint.Env.Dir = "migrations"
+	pr := &PullRequest{Number: 1}
+	require.NoError(t, c.upsertFileThreads(context.Background(), pr, "db", lint))
+	require.Len(t, threads, 1)
+	require.Equal(t, &azuredevops.ThreadContext{
+		FilePath:       "/db/migrations/1.sql",
+		RightFileStart: &azuredevops.FilePosition{Line: 2, Offset: 1},
+		RightFileEnd:   &azuredevops.FilePosition{Line: 2, Offset: 1},
+	}, threads[0].ThreadContext)
+	require.True(t, strings.HasPrefix(threads[0].Comments[0].Content, "**destructive changes detected**\n\nDropping table \"t\" [DS102](https://atlasgo.io/lint/analyzers#DS102)\n<!-- generated by ariga/atlas-action for lint-"))
+
+	// Existing threads are not duplicated.
+	require.NoError(t, c.upsertFileThreads(context.Background(), pr, "db", lint))
+	require.Len(t, threads, 1)
+	require.Empty(t, statuses)
+
+	// Fixed findings are resolved.
+	lint.Files[0].Reports = nil
+	lint.Files[0].Error = ""
+	require.NoError(t, c.upsertFileThreads(context.Background(), pr, "db", lint))
+	require.Len(t, threads, 1)
+	require.Equal(t, map[string]string{"1": "fixed"}, statuses)
+
+	// Findings that come back reopen the threads resolved by the action.
+	threads[0].Status = azuredevops.ThreadStatusFixed
+	lint.Files[0].Reports = []sqlcheck.Report{{
+		Text:        "destructive changes detected",
+		Diagnostics: []sqlcheck.Diagnostic{{Pos: 23, Text: `Dropping table "t"`, Code: "DS102"}},
+	}}
+	lint.Files[0].Error = "destructive changes detected"
+	require.NoError(t, c.upsertFileThreads(context.Background(), pr, "db", lint))
+	require.Len(t, threads, 1)
+	require.Equal(t, map[string]string{"1": "active"}, statuses)
+
+	// Statuses set by reviewers are kept.
+	clear(statuses)
+	threads[0].Status = "wontFix"
+	require.NoError(t, c.upsertFileThreads(context.Background(), pr, "db", lint))
+	require.Len(t, threads, 1)
+	require.Empty(t, statuses)
 }
 
 // mockAction implements the Action interface for testing
@@ -329,3 +449,55 @@ func cmpFiles(ts *testscript.TestScript, neg bool, name1, name2 string) {
 	ts.Logf("%s", unifiedDiff)
 	ts.Fatalf("%s and %s differ", name1, name2)
 }
+
+func TestAzure_Reporter(t *testing.T) {
+	var (
+		out strings.Builder
+		dir = t.TempDir()
+		env = map[string]string{"AGENT_TEMPDIRECTORY": dir}
+	)
+	a := NewAzure(func(k string) string { return env[k] }, &out)
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{{
+			Name: "1.sql",
+			Text: "CREATE TABLE t(c int);\nALTER TABLE t ADD COLUMN d int NOT NULL;\n",
+			Reports: []sqlcheck.Report{{
+				Text: "data dependent changes detected",
+				Diagnostics: []sqlcheck.Diagnostic{
+					{Pos: 23, Text: `Adding a non-nullable "int" column "d"`, Code: "MY101"},
+				},
+			}},
+		}},
+	}
+	lint.Env.Dir = "migrations"
+	a.MigrateLint(context.Background(), lint)
+	require.Equal(t, `##vso[task.logissue code=MY101;linenumber=2;sourcepath=migrations/1.sql;type=warning;]data dependent changes detected: Adding a non-nullable "int" column "d" (MY101)
+##vso[task.uploadsummary]`+filepath.Join(dir, "atlas-migrate-lint.md")+`
+##vso[task.complete result=SucceededWithIssues;]Atlas lint found 1 warnings
+`, out.String())
+	require.FileExists(t, filepath.Join(dir, "atlas-migrate-lint.md"))
+
+	out.Reset()
+	pos := &schema.Pos{Filename: "schema.hcl"}
+	pos.Start.Line = 5
+	a.SchemaLint(context.Background(), &SchemaLintReport{
+		SchemaLintReport: &atlasexec.SchemaLintReport{
+			Steps: []atlasexec.Report{{
+				Text:  "naming",
+				Error: true,
+				Diagnostics: []atlasexec.Diagnostic{
+					{Text: "Table name should use snake_case", Code: "NM101", Pos: pos},
+				},
+			}},
+		},
+	})
+	require.Equal(t, `##vso[task.logissue code=NM101;linenumber=5;sourcepath=schema.hcl;type=error;]naming: Table name should use snake_case (NM101)
+##vso[task.uploadsummary]`+filepath.Join(dir, "atlas-schema-lint.md")+`
+##vso[task.complete result=Failed;]Atlas lint found 1 errors
+`, out.String())
+
+	// Summaries of multiple runs are written to separate files.
+	out.Reset()
+	a.MigrateLint(context.Background(), &atlasexec.SummaryReport{})
+	require.Equal(t, "##vso[task.uploadsummary]"+filepath.Join(dir, "atlas-migrate-lint-1.md")+"\n", out.String())
+}
@@ -5,6 +5,7 @@
 package atlasaction
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
@@ -64,6 +65,7 @@ func (a *Bitbucket) GetTriggerContext(context.Context) (*TriggerContext, error)
 			return NewBitbucketClient(
 				a.Getenv("BITBUCKET_WORKSPACE"),
 				a.Getenv("BITBUCKET_REPO_SLUG"),
+				"",
 				token,
 			)
 		},
@@ -121,98 +123,52 @@ func (a *Bitbucket) SetOutput(name, value string) {
 }
 
 // MigrateApply implements Reporter.
-func (a *Bitbucket) MigrateApply(context.Context, *atlasexec.MigrateApply) {
+func (a *Bitbucket) MigrateApply(ctx context.Context, r *atlasexec.MigrateApply) {
+	commitID := a.getenv("BITBUCKET_COMMIT")
+	cr, err := MigrateApplyReport(commitID, r)
+	if err != nil {
+		a.Errorf("failed to generate commit report: %v", err)
+		return
+	}
+	a.createReport(ctx, commitID, cr, nil)
 }
 
 // SchemaLint implements Reporter.
-func (a *Bitbucket) SchemaLint(context.Context, *SchemaLintReport) {
+func (a *Bitbucket) SchemaLint(ctx context.Context, r *SchemaLintReport) {
+	commitID := a.getenv("BITBUCKET_COMMIT")
+	cr, annos, err := SchemaLintCommitReport(commitID, a.GetInput("working-directory"), r)
+	if err != nil {
+		a.Errorf("failed to generate commit report: %v", err)
+		return
+	}
+	a.createReport(ctx, commitID, cr, annos)
 }
 
 // MigrateLint implements Reporter.
 func (a *Bitbucket) MigrateLint(ctx context.Context, r *atlasexec.SummaryReport) {
-	c, err := a.reportClient()
-	if err != nil {
-		a.Errorf("failed to create Bitbucket client: %v", err)
-		return
-	}
 	commitID := a.getenv("BITBUCKET_COMMIT")
 	cr, err := LintReport(commitID, r)
 	if err != nil {
 		a.Errorf("failed to generate commit report: %v", err)
 		return
 	}
-	if _, err = c.CreateReport(ctx, commitID, cr); err != nil {
-		a.Errorf("failed to create commit report: %v", err)
+	annos, err := LintAnnotations(cr.ExternalID, a.GetInput("working-directory"), r)
+	if err != nil {
+		a.Errorf("failed to generate commit report annotations: %v", err)
 		return
 	}
-	if issues := filterIssues(r.Steps); len(issues) > 0 {
-		stepSummary := func(s *atlasexec.StepReport) string {
-			if s.Text == "" {
-				return s.Name
-			}
-			return fmt.Sprintf("%s: %s", s.Name, s.Text)
-		}
-		annos := make([]bitbucket.ReportAnnotation, 0, len(issues))
-		for _, s := range issues {
-			severity := bitbucket.SeverityMedium
-			if stepIsError(s) {
-				severity = bitbucket.SeverityHigh
-			}
-			if s.Result == nil {
-				anno := bitbucket.ReportAnnotation{
-					Result:   bitbucket.ResultFailed,
-					Summary:  stepSummary(s),
-					Details:  s.Error,
-					Severity: severity,
-				}
-				anno.ExternalID, err = hash(cr.ExternalID, s.Name, s.Text)
-				if err != nil {
-					a.Errorf("failed to generate external ID: %v", err)
-					return
-				}
-				annos = append(annos, anno)
-			} else {
-				for _, rr := range s.Result.Reports {
-					for _, d := range rr.Diagnostics {
-						anno := bitbucket.ReportAnnotation{
-							Result:   bitbucket.ResultFailed,
-							Summary:  stepSummary(s),
-							Details:  fmt.Sprintf("%s: %s", rr.Text, d.Text),
-							Severity: severity,
-							Path:     "", // TODO: add path
-							Line:     0,  // TODO: add line
-						}
-						switch {
-						case d.Code != "":
-							anno.Details += fmt.Sprintf(" (%s)", d.Code)
-							anno.AnnotationType = bitbucket.AnnotationTypeBug
-							anno.Link = fmt.Sprintf("https://atlasgo.io/lint/analyzers#%s", d.Code)
-						case len(d.SuggestedFixes) != 0:
-							anno.AnnotationType = bitbucket.AnnotationTypeCodeSmell
-							// TODO: Add suggested fixes.
-						default:
-							anno.AnnotationType = bitbucket.AnnotationTypeVulnerability
-						}
-						anno.ExternalID, err = hash(cr.ExternalID, s.Name, s.Text, rr.Text, d.Text)
-						if err != nil {
-							a.Errorf("failed to generate external ID: %v", err)
-							return
-						}
-						annos = append(annos, anno)
-					}
-				}
-			}
-		}
-		_, err = c.CreateReportAnnotations(ctx, commitID, cr.ExternalID, annos)
-		if err != nil {
-			a.Errorf("failed to create commit report annotations: %v", err)
-			return
-		}
-	}
+	a.createReport(ctx, commitID, cr, annos)
 }
 
 // SchemaApply implements Reporter.
-func (a *Bitbucket) SchemaApply(context.Context, *atlasexec.SchemaApply) {
+func (a *Bitbucket) SchemaApply(ctx context.Context, r *atlasexec.SchemaApply) {
+	commitID := a.getenv("BITBUCKET_COMMIT")
+	cr, err := SchemaApplyReport(commitID, r)
+	if err != nil {
+		a.Errorf("failed to generate commit report: %v", err)
+		return
+	}
+	a.createReport(ctx, commitID, cr, nil)
 }
 
 // SchemaPlan implements Reporter.
@@ -222,6 +178,26 @@ func (a *Bitbucket) SchemaPlan(ctx context.Context, r *atlasexec.SchemaPlan) {
 	}
 }
 
+// createReport creates the commit report and its annotations.
+func (a *Bitbucket) createReport(ctx context.Context, commitID string, cr *bitbucket.CommitReport, annos []bitbucket.ReportAnnotation) {
+	c, err := a.reportClient()
+	if err != nil {
+		a.Errorf("failed to create Bitbucket client: %v", err)
+		return
+	}
+	if _, err = c.CreateReport(ctx, commitID, cr); err != nil {
+		a.Errorf("failed to create commit report: %v", err)
+		return
+	}
+	// The API accepts up to 100 annotations per request.
+	for chunk := range slices.Chunk(annos, 100) {
+		if _, err = c.CreateReportAnnotations(ctx, commitID, cr.ExternalID, chunk); err != nil {
+			a.Errorf("failed to create commit report annotations: %v", err)
+			return
+		}
+	}
+}
+
 // reportClient returns a new Bitbucket client,
 // This client only works with the Reports-API.
 func (a *Bitbucket) reportClient() (*bitbucket.Client, error) {
@@ -293,14 +269,254 @@ func LintReport(commit string, r *atlasexec.SummaryReport) (*bitbucket.CommitRep
 	return cr, nil
 }
 
+// LintAnnotations generates the annotations of the commit report for the given lint report.
+// The paths of the annotated files are relative to the repository root.
+func LintAnnotations(reportID, wd string, r *atlasexec.SummaryReport) ([]bitbucket.ReportAnnotation, error) {
+	issues := filterIssues(r.Steps)
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	stepSummary := func(s *atlasexec.StepReport) string {
+		if s.Text == "" {
+			return s.Name
+		}
+		return fmt.Sprintf("%s: %s", s.Name, s.Text)
+	}
+	annos := make([]bitbucket.ReportAnnotation, 0, len(issues))
+	for _, s := range issues {
+		severity := bitbucket.SeverityMedium
+		if stepIsError(s) {
+			severity = bitbucket.SeverityHigh
+		}
+		if s.Result == nil {
+			anno := bitbucket.ReportAnnotation{
+				Result:   bitbucket.ResultFailed,
+				Summary:  stepSummary(s),
+				Details:  s.Error,
+				Severity: severity,
+			}
+			var err error
+			anno.ExternalID, err = hash(reportID, s.Name, s.Text)
+			if err != nil {
+				return nil, fmt.Errorf("bitbucket: failed to generate external ID: %w", err)
+			}
+			annos = append(annos, anno)
+			continue
+		}
+		path := filepath.ToSlash(filepath.Join(wd, r.Env.Dir, s.Result.Name))
+		for _, rr := range s.Result.Reports {
+			for _, d := range rr.Diagnostics {
+				anno := bitbucket.ReportAnnotation{
+					Result:   bitbucket.ResultFailed,
+					Summary:  stepSummary(s),
+					Details:  fmt.Sprintf("%s: %s", rr.Text, d.Text),
+					Severity: severity,
+					Path:     path,
+					Line:     diagnosticLine(s.Result, d.Pos),
+				}
+				switch {
+				case d.Code != "":
+					anno.Details += fmt.Sprintf(" (%s)", d.Code)
+					anno.AnnotationType = bitbucket.AnnotationTypeBug
+					anno.Link = fmt.Sprintf("https://atlasgo.io/lint/analyzers#%s", d.Code)
+				case len(d.SuggestedFixes) != 0:
+					anno.AnnotationType = bitbucket.AnnotationTypeCodeSmell
+					// TODO: Add suggested fixes.
+				default:
+					anno.AnnotationType = bitbucket.AnnotationTypeVulnerability
+				}
+				var err error
+				anno.ExternalID, err = hash(reportID, s.Name, s.Text, rr.Text, d.Text, strconv.Itoa(d.Pos))
+				if err != nil {
+					return nil, fmt.Errorf("bitbucket: failed to generate external ID: %w", err)
+				}
+				annos = append(annos, anno)
+			}
+		}
+	}
+	return annos, nil
+}
+
+// SchemaLintCommitReport generates a commit report and its annotations for the given schema lint report.
+func SchemaLintCommitReport(commit, wd string, r *SchemaLintReport) (*bitbucket.CommitReport, []bitbucket.ReportAnnotation, error) {
+	externalID, err := hash(commit, CmdSchemaLint, strings.Join(r.URL, ","))
+	if err != nil {
+		return nil, nil, fmt.Errorf("bitbucket: failed to generate external ID: %w", err)
+	}
+	cr := &bitbucket.CommitReport{
+		ExternalID: externalID,
+		Reporter:   bitbucketReporter,
+		ReportType: bitbucket.ReportTypeSecurity,
+		Title:      "Atlas Schema Lint",
+		LogoURL:    "https://atlasgo.io/uploads/websiteicon.svg",
+		Result:     bitbucket.ResultPassed,
+		Details:    "No issues found.",
+	}
+	var (
+		annos       []bitbucket.ReportAnnotation
+		errs, diags int
+	)
+	for _, s := range r.Steps {
+		severity := bitbucket.SeverityMedium
+		if s.Error {
+			severity = bitbucket.SeverityHigh
+			errs++
+		}
+		for _, d := range s.Diagnostics {
+			diags++
+			anno := bitbucket.ReportAnnotation{
+				AnnotationType: bitbucket.AnnotationTypeCodeSmell,
+				Result:         bitbucket.ResultFailed,
+				Summary:        s.Text,
+				Details:        diagnosticText(d.Text, d.Code),
+				Severity:       severity,
+			}
+			if d.Code != "" {
+				anno.AnnotationType = bitbucket.AnnotationTypeBug
+				anno.Link = fmt.Sprintf("https://atlasgo.io/lint/analyzers#%s", d.Code)
+			}
+			var pos string
+			if p := d.Pos; p != nil {
+				path := p.Filename
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(wd, path)
+				}
+				anno.Path = filepath.ToSlash(path)
+				anno.Line = max(1, p.Start.Line)
+				pos = fmt.Sprintf("%s:%d", anno.Path, anno.Line)
+			}
+			if anno.ExternalID, err = hash(externalID, s.Text, d.Text, d.Code, pos); err != nil {
+				return nil, nil, fmt.Errorf("bitbucket: failed to generate external ID: %w", err)
+			}
+			annos = append(annos, anno)
+		}
+	}
+	if diags > 0 {
+		cr.Details = fmt.Sprintf("Found %d issues.", diags)
+	}
+	if errs > 0 {
+		cr.Result = bitbucket.ResultFailed
+	}
+	cr.AddNumber("Steps", int64(len(r.Steps)))
+	cr.AddNumber("Errors", int64(errs))
+	cr.AddNumber("Diagnostics", int64(diags))
+	if len(r.URL) > 0 {
+		cr.AddText("Schema", strings.Join(r.URL, ", "))
+	}
+	return cr, annos, nil
+}
+
+// MigrateApplyReport generates a commit report for the given migrate apply run.
+func MigrateApplyReport(commit string, r *atlasexec.MigrateApply) (*bitbucket.CommitReport, error) {
+	target := redactedEnvURL(r.Env)
+	externalID, err := hash(commit, CmdMigrateApply, target, r.Env.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket: failed to generate external ID: %w", err)
+	}
+	cr := &bitbucket.CommitReport{
+		ExternalID: externalID,
+		Reporter:   bitbucketReporter,
+		ReportType: bitbucket.ReportTypeTest,
+		Title:      "Atlas Migrate Apply",
+		LogoURL:    "https://atlasgo.io/uploads/websiteicon.svg",
+		Result:     bitbucket.ResultPassed,
+	}
+	var stmts int
+	for _, f := range r.Applied {
+		stmts += len(f.Applied)
+	}
+	switch {
+	case r.Error != "":
+		cr.Result = bitbucket.ResultFailed
+		cr.Details = fmt.Sprintf("Migration failed: %s", r.Error)
+	case len(r.Applied) == 0:
+		cr.Details = fmt.Sprintf("No migrations to apply, the database is at version %s.", r.Current)
+	default:
+		cr.Details = fmt.Sprintf("Migrated from version %s to %s.", cmp.Or(r.Current, "(none)"), r.Target)
+	}
+	cr.AddText("Current Version", cmp.Or(r.Current, "-"))
+	cr.AddText("Target Version", cmp.Or(r.Target, "-"))
+	cr.AddNumber("Applied Files", int64(len(r.Applied)))
+	cr.AddNumber("Pending Files", int64(len(r.Pending)))
+	cr.AddNumber("Statements", int64(stmts))
+	if !r.Start.IsZero() && !r.End.IsZero() {
+		cr.AddDuration("Duration", r.End.Sub(r.Start))
+	}
+	if target != "" {
+		cr.AddText("Target", target)
+	}
+	if d := r.Env.Dir; d != "" {
+		cr.AddText("Migration Directory", d)
+	}
+	return cr, nil
+}
+
+// SchemaApplyReport generates a commit report for the given schema apply run.
+func SchemaApplyReport(commit string, r *atlasexec.SchemaApply) (*bitbucket.CommitReport, error) {
+	target := redactedEnvURL(r.Env)
+	externalID, err := hash(commit, CmdSchemaApply, target)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket: failed to generate external ID: %w", err)
+	}
+	cr := &bitbucket.CommitReport{
+		ExternalID: externalID,
+		Reporter:   bitbucketReporter,
+		ReportType: bitbucket.ReportTypeTest,
+		Title:      "Atlas Schema Apply",
+		LogoURL:    "https://atlasgo.io/uploads/websiteicon.svg",
+		Result:     bitbucket.ResultPassed,
+	}
+	var stmts int
+	if a := r.Applied; a != nil {
+		stmts = len(a.Applied)
+	}
+	switch {
+	case r.Error != "":
+		cr.Result = bitbucket.ResultFailed
+		cr.Details = fmt.Sprintf("Schema apply failed: %s", r.Error)
+	case r.Applied != nil && r.Applied.Error != nil:
+		cr.Result = bitbucket.ResultFailed
+		cr.Details = fmt.Sprintf("Schema apply failed after %d statement(s): %s", stmts, r.Applied.Error.Text)
+	case stmts == 0:
+		cr.Details = "No schema changes to apply."
+	default:
+		cr.Details = fmt.Sprintf("Applied %d statement(s).", stmts)
+	}
+	cr.AddNumber("Statements", int64(stmts))
+	if !r.Start.IsZero() && !r.End.IsZero() {
+		cr.AddDuration("Duration", r.End.Sub(r.Start))
+	}
+	if target != "" {
+		cr.AddText("Target", target)
+	}
+	if p := r.Plan; p != nil && p.File != nil {
+		cr.AddText("Plan", p.File.Name)
+		if p.File.Link != "" {
+			if u, err := url.Parse(p.File.Link); err == nil {
+				cr.AddLink("Plan Link", "View Plan", u)
+			}
+		}
+	}
+	return cr, nil
+}
+
+// redactedEnvURL returns the redacted URL of the target database.
+func redactedEnvURL(env atlasexec.Env) string {
+	if env.URL == nil || env.URL.URL == nil {
+		return ""
+	}
+	return env.URL.Redacted()
+}
+
 type BitbucketClient struct {
 	*bitbucket.Client
 }
 
 // NewBitbucketClient returns a new Bitbucket client that implements SCMClient.
-func NewBitbucketClient(workspace, repoSlug, token string) (*BitbucketClient, error) {
+func NewBitbucketClient(workspace, repoSlug, baseURL, token string) (*BitbucketClient, error) {
 	c, err := bitbucket.NewClient(
 		workspace, repoSlug,
+		bitbucket.WithBaseURL(baseURL),
 		bitbucket.WithToken(&oauth2.Token{AccessToken: token}),
 	)
 	if err != nil {
@@ -310,23 +526,51 @@ func NewBitbucketClient(workspace, repoSlug, token string) (*BitbucketClient, er
 }
 
 // PullRequest implements SCMClient.
-func (c *BitbucketClient) PullRequest(context.Context, int) (*PullRequest, error) {
-	panic("unimplemented: PullRequest for BitbucketClient")
+func (c *BitbucketClient) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	pr, err := c.Client.PullRequest(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return convertBitbucketPullRequest(pr), nil
 }
 
 // CreatePullRequest implements SCMClient.
-func (c *BitbucketClient) CreatePullRequest(_ context.Context, _, _, _, _ string) (*PullRequest, error) {
-	panic("unimplemented: CreatePullRequest for BitbucketClient")
+func (c *BitbucketClient) CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	pr, err := c.Client.CreatePullRequest(ctx, head, base, title, body)
+	if err != nil {
+		return nil, err
+	}
+	return convertBitbucketPullRequest(pr), nil
 }
 
 // CopilotSession implements SCMClient.
-func (c *BitbucketClient) CopilotSession(context.Context, *TriggerContext) (string, error) {
-	panic("unimplemented: CopilotSession for BitbucketClient")
+func (c *BitbucketClient) CopilotSession(ctx context.Context, tc *TriggerContext) (string, error) {
+	cs, err := c.PullRequestComments(ctx, func() int {
+		if tc.PullRequest != nil {
+			return tc.PullRequest.Number
+		}
+		return tc.Comment.Number
+	}())
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cs {
+		if m := reCopilotSession.FindStringSubmatch(c.Content.Raw); !c.Deleted && len(m) > 1 {
+			return m[1], nil
+		}
+	}
+	return "", nil
 }
 
 // CommentCopilot implements SCMClient.
-func (c *BitbucketClient) CommentCopilot(context.Context, int, *Copilot) error {
-	panic("unimplemented: CommentCopilot for BitbucketClient")
+func (c *BitbucketClient) CommentCopilot(ctx context.Context, pr int, cp *Copilot) error {
+	var buf strings.Builder
+	if cp.Prompt != "" {
+		fmt.Fprintf(&buf, "> %s\n\n", cp.Prompt)
+	}
+	fmt.Fprintf(&buf, copilotSession, cp.Response, cp.Session)
+	_, err := c.PullRequestCreateComment(ctx, pr, buf.String())
+	return err
 }
 
 // CommentLint implements SCMClient.
@@ -348,7 +592,32 @@ func (c *BitbucketClient) CommentPlan(ctx context.Context, tc *TriggerContext, p
 }
 
 // CommentSchemaLint implements SCMClient.
-func (c *BitbucketClient) CommentSchemaLint(context.Context, *TriggerContext, *SchemaLintReport) error {
+func (c *BitbucketClient) CommentSchemaLint(ctx context.Context, tc *TriggerContext, r *SchemaLintReport) error {
+	id := schemaLintCommentID(tc)
+	if len(r.Steps) == 0 {
+		return c.deleteComment(ctx, tc.PullRequest, id)
+	}
+	comment, err := RenderTemplate("schema-lint/md", r, tc)
+	if err != nil {
+		return err
+	}
+	return c.upsertComment(ctx, tc.PullRequest, id, comment)
+}
+
+func (c *BitbucketClient) deleteComment(ctx context.Context, pr *PullRequest, id string) error {
+	if pr == nil {
+		return fmt.Errorf("pull request is required for commenting")
+	}
+	comments, err := c.PullRequestComments(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+	marker := commentMarker(id)
+	if found := slices.IndexFunc(comments, func(c bitbucket.PullRequestComment) bool {
+		return !c.Deleted && strings.Contains(c.Content.Raw, marker)
+	}); found != -1 {
+		return c.PullRequestDeleteComment(ctx, pr.Number, comments[found].ID)
+	}
 	return nil
 }
 
@@ -363,7 +632,7 @@ func (c *BitbucketClient) upsertComment(ctx context.Context, pr *PullRequest, id
 	marker := commentMarker(id)
 	comment += "\n\n" + marker
 	if found := slices.IndexFunc(comments, func(c bitbucket.PullRequestComment) bool {
-		return strings.Contains(c.Content.Raw, marker)
+		return !c.Deleted && strings.Contains(c.Content.Raw, marker)
 	}); found != -1 {
 		_, err = c.PullRequestUpdateComment(ctx, pr.Number, comments[found].ID, comment)
 	} else {
@@ -372,6 +641,25 @@ func (c *BitbucketClient) upsertComment(ctx context.Context, pr *PullRequest, id
 	return err
 }
 
+// convertBitbucketPullRequest converts a Bitbucket pull request to a PullRequest.
+func convertBitbucketPullRequest(pr *bitbucket.PullRequest) *PullRequest {
+	if pr == nil {
+		return nil
+	}
+	r := &PullRequest{
+		Number: pr.ID,
+		Body:   pr.Description,
+		Ref:    pr.Source.Branch.Name,
+	}
+	if c := pr.Source.Commit; c != nil {
+		r.Commit = c.Hash
+	}
+	if l := pr.Links; l != nil {
+		r.URL = l.HTML.Href
+	}
+	return r
+}
+
 // hash returns the SHA-256 hash of the parts.
 // The hash is encoded using base64.RawURLEncoding.
 func hash(parts ...string) (string, error) {
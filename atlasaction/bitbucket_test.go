@@ -1,10 +1,18 @@
 package atlasaction_test
 
 import (
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas-action/internal/bitbucket"
+	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
 	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/stretchr/testify/require"
 )
@@ -50,3 +58,114 @@ func TestBitbucketPipe(t *testing.T) {
 		},
 	})
 }
+
+func TestBitbucket_LintAnnotations(t *testing.T) {
+	lint := &atlasexec.SummaryReport{
+		Steps: []*atlasexec.StepReport{
+			{
+				Name: "Analyze 1.sql",
+				Text: "1 reports were found in analysis",
+				Result: &atlasexec.FileReport{
+					Name: "1.sql",
+					Text: "CREATE TABLE t(c int);\nDROP TABLE t;\n",
+					Reports: []sqlcheck.Report{{
+						Text: "destructive changes detected",
+						Diagnostics: []sqlcheck.Diagnostic{
+							{Pos: 23, Text: `Dropping table "t"`, Code: "DS102"},
+						},
+					}},
+					Error: "destructive changes detected",
+				},
+			},
+			{Name: "Migration Integrity Check", Error: "checksum mismatch"},
+		},
+	}
+	lint.Env.Dir = "migrations"
+	annos, err := atlasaction.LintAnnotations("report", "db", lint)
+	require.NoError(t, err)
+	require.Len(t, annos, 2)
+	require.Equal(t, "db/migrations/1.sql", annos[0].Path)
+	require.Equal(t, 2, annos[0].Line)
+	require.Equal(t, bitbucket.SeverityHigh, annos[0].Severity)
+	require.Equal(t, bitbucket.AnnotationTypeBug, annos[0].AnnotationType)
+	require.Equal(t, "destructive changes detected: Dropping table \"t\" (DS102)", annos[0].Details)
+	require.Equal(t, "https://atlasgo.io/lint/analyzers#DS102", annos[0].Link)
+	require.Empty(t, annos[1].Path)
+	require.Equal(t, "checksum mismatch", annos[1].Details)
+	require.NotEqual(t, annos[0].ExternalID, annos[1].ExternalID)
+}
+
+func TestBitbucket_SchemaLintCommitReport(t *testing.T) {
+	pos := &schema.Pos{Filename: "schema.hcl"}
+	pos.Start.Line = 5
+	cr, annos, err := atlasaction.SchemaLintCommitReport("sha", "db", &atlasaction.SchemaLintReport{
+		URL: []string{"file://schema.hcl"},
+		SchemaLintReport: &atlasexec.SchemaLintReport{
+			Steps: []atlasexec.Report{{
+				Text:  "naming",
+				Error: true,
+				Diagnostics: []atlasexec.Diagnostic{
+					{Text: "Table name should use snake_case", Code: "NM101", Pos: pos},
+					{Text: "Column name should use snake_case"},
+				},
+			}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Atlas Schema Lint", cr.Title)
+	require.Equal(t, bitbucket.ResultFailed, cr.Result)
+	require.Equal(t, "Found 2 issues.", cr.Details)
+	require.Len(t, annos, 2)
+	require.Equal(t, "db/schema.hcl", annos[0].Path)
+	require.Equal(t, 5, annos[0].Line)
+	require.Equal(t, bitbucket.SeverityHigh, annos[0].Severity)
+	require.Equal(t, bitbucket.AnnotationTypeBug, annos[0].AnnotationType)
+	require.Empty(t, annos[1].Path)
+	require.Equal(t, bitbucket.AnnotationTypeCodeSmell, annos[1].AnnotationType)
+
+	// No issues.
+	cr, annos, err = atlasaction.SchemaLintCommitReport("sha", "", &atlasaction.SchemaLintReport{
+		SchemaLintReport: &atlasexec.SchemaLintReport{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, bitbucket.ResultPassed, cr.Result)
+	require.Empty(t, annos)
+}
+
+func TestBitbucket_ApplyReports(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	env := atlasexec.Env{
+		Dir: "migrations",
+		URL: &sqlclient.URL{URL: &url.URL{Scheme: "sqlite", Host: "file"}},
+	}
+	cr, err := atlasaction.MigrateApplyReport("sha", &atlasexec.MigrateApply{
+		Env:     env,
+		Current: "1",
+		Target:  "3",
+		Applied: []*atlasexec.AppliedFile{
+			{Applied: []string{"CREATE TABLE t1(c int);"}},
+			{Applied: []string{"CREATE TABLE t2(c int);", "CREATE TABLE t3(c int);"}},
+		},
+		Start: start,
+		End:   start.Add(2 * time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Atlas Migrate Apply", cr.Title)
+	require.Equal(t, bitbucket.ResultPassed, cr.Result)
+	require.Equal(t, "Migrated from version 1 to 3.", cr.Details)
+	require.LessOrEqual(t, len(cr.Data), 10)
+	other, err := atlasaction.MigrateApplyReport("sha", &atlasexec.MigrateApply{
+		Env: atlasexec.Env{URL: &sqlclient.URL{URL: &url.URL{Scheme: "sqlite", Host: "other"}}},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, cr.ExternalID, other.ExternalID, "reports of different targets must not collide")
+
+	cr, err = atlasaction.SchemaApplyReport("sha", &atlasexec.SchemaApply{
+		Env:   env,
+		Error: "connection refused",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Atlas Schema Apply", cr.Title)
+	require.Equal(t, bitbucket.ResultFailed, cr.Result)
+	require.Equal(t, "Schema apply failed: connection refused", cr.Details)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+)
+
+// Buildkite is an implementation of the Action interface for Buildkite.
+type Buildkite struct {
+	*coloredLogger
+	getenv func(string) string
+}
+
+var (
+	_ Action   = (*Buildkite)(nil)
+	_ Reporter = (*Buildkite)(nil)
+)
+
+// NewBuildkite returns a new Buildkite.
+func NewBuildkite(getenv func(string) string, w io.Writer) *Buildkite {
+	return &Buildkite{getenv: getenv, coloredLogger: &coloredLogger{w}}
+}
+
+// GetType implements the Action interface.
+func (a *Buildkite) GetType() atlasexec.TriggerType {
+	return atlasexec.TriggerType("BUILDKITE")
+}
+
+// Getenv implements Action.
+func (a *Buildkite) Getenv(key string) string {
+	return a.getenv(key)
+}
+
+// GetInput implements the Action interface.
+func (a *Buildkite) GetInput(name string) string {
+	if v := a.getenv(toInputVarName(name)); v != "" {
+		return strings.TrimSpace(v)
+	}
+	// Inputs can be passed as plugin configuration, which Buildkite
+	// exposes as environment variables. Lists are exposed with
+	// an index suffix, one variable per item. The command itself
+	// is set using the ATLAS_ACTION environment variable.
+	//
+	//	plugins:
+	//	  - ariga/atlas#v1:
+	//	      dir: file://migrations
+	//	      schema: [public, private]
+	//
+	// BUILDKITE_PLUGIN_ATLAS_DIR=file://migrations
+	// BUILDKITE_PLUGIN_ATLAS_SCHEMA_0=public
+	// BUILDKITE_PLUGIN_ATLAS_SCHEMA_1=private
+	key := "BUILDKITE_PLUGIN_ATLAS_" + toEnvName(name)
+	if v := a.getenv(key); v != "" {
+		return strings.TrimSpace(v)
+	}
+	var vs []string
+	for i := 0; ; i++ {
+		v := a.getenv(key + "_" + strconv.Itoa(i))
+		if v == "" {
+			break
+		}
+		vs = append(vs, strings.TrimSpace(v))
+	}
+	return strings.Join(vs, "\n")
+}
+
+// SetOutput implements the Action interface.
+// The outputs are stored as build meta-data, and can be read by later steps:
+//
+//	buildkite-agent meta-data get ATLAS_OUTPUT_MIGRATE_LINT_REPORT_URL
+func (a *Buildkite) SetOutput(name, value string) {
+	key := toOutputVarName(a.getenv("ATLAS_ACTION_COMMAND"), name)
+	// The value is read from stdin, if omitted from the arguments.
+	if err := a.agent(strings.NewReader(value), "meta-data", "set", key); err != nil {
+		a.Errorf("failed to set output %s: %v", name, err)
+	}
+}
+
+// GetTriggerContext implements the Action interface.
+// https://buildkite.com/docs/pipelines/configure/environment-variables
+func (a *Buildkite) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc := &TriggerContext{
+		Act:           a,
+		Branch:        a.getenv("BUILDKITE_BRANCH"),
+		Commit:        a.getenv("BUILDKITE_COMMIT"),
+		DefaultBranch: a.getenv("BUILDKITE_PIPELINE_DEFAULT_BRANCH"),
+	}
+	if tc.Commit == "" {
+		return nil, fmt.Errorf("missing BUILDKITE_COMMIT environment variable")
+	}
+	if u := a.getenv("BUILDKITE_REPO"); u != "" {
+		var err error
+		if tc.RepoURL, tc.Repo, err = parseRemoteURL(u); err != nil {
+			return nil, err
+		}
+	}
+	if u := a.getenv("BUILDKITE_BUILD_CREATOR"); u != "" {
+		tc.Actor = &Actor{Name: u}
+	}
+	// BUILDKITE_PULL_REQUEST is set to "false" for builds not triggered by a pull request.
+	if pr := a.getenv("BUILDKITE_PULL_REQUEST"); pr != "" && pr != "false" {
+		n, err := strconv.Atoi(pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BUILDKITE_PULL_REQUEST: %w", err)
+		}
+		tc.PullRequest = &PullRequest{
+			Number: n,
+			Commit: tc.Commit,
+		}
+		if b := a.getenv("BUILDKITE_PULL_REQUEST_BASE_BRANCH"); b != "" {
+			tc.DefaultBranch = b
+		}
+	}
+	if err := tc.detectSCM(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// MigrateApply implements Reporter.
+func (a *Buildkite) MigrateApply(_ context.Context, r *atlasexec.MigrateApply) {
+	style := "success"
+	if r.Error != "" {
+		style = "error"
+	}
+	a.annotate("migrate-apply.tmpl", r, style)
+}
+
+// MigrateLint implements Reporter.
+func (a *Buildkite) MigrateLint(_ context.Context, r *atlasexec.SummaryReport) {
+	a.annotate("migrate-lint.tmpl", r, lintStyle(r))
+}
+
+// SchemaPlan implements Reporter.
+func (a *Buildkite) SchemaPlan(_ context.Context, r *atlasexec.SchemaPlan) {
+	style := "info"
+	if r.Lint != nil {
+		style = lintStyle(r.Lint)
+	}
+	a.annotate("schema-plan.tmpl", map[string]any{
+		"Plan": r,
+	}, style)
+}
+
+// SchemaApply implements Reporter.
+func (a *Buildkite) SchemaApply(_ context.Context, r *atlasexec.SchemaApply) {
+	style := "success"
+	if r.Error != "" {
+		style = "error"
+	}
+	a.annotate("schema-apply.tmpl", r, style)
+}
+
+// SchemaLint implements Reporter.
+func (a *Buildkite) SchemaLint(_ context.Context, r *SchemaLintReport) {
+	style := "success"
+	if len(r.Steps) > 0 {
+		style = "warning"
+	}
+	for _, s := range r.Steps {
+		if s.Error {
+			style = "error"
+			break
+		}
+	}
+	a.annotate("schema-lint.tmpl", r, style)
+}
+
+// annotate renders the given template and adds it as an annotation to the build.
+// Annotations are scoped by the running action, so rerunning the same
+// action in a build replaces the existing annotation.
+func (a *Buildkite) annotate(name string, data any, style string) {
+	body, err := RenderTemplate(name, data, nil)
+	if err != nil {
+		a.Errorf("failed to create summary: %v", err)
+		return
+	}
+	ctx := "atlas"
+	if act := a.getenv("ATLAS_ACTION_COMMAND"); act != "" {
+		ctx = "atlas-" + strings.ReplaceAll(act, "/", "-")
+	}
+	// The body is read from stdin, if omitted from the arguments.
+	if err = a.agent(strings.NewReader(body), "annotate", "--style", style, "--context", ctx); err != nil {
+		a.Errorf("failed to annotate the build: %v", err)
+	}
+}
+
+// agent runs the buildkite-agent command with the given arguments.
+func (a *Buildkite) agent(stdin io.Reader, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("buildkite-agent", args...)
+	cmd.Stdin, cmd.Stderr = stdin, &stderr
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("buildkite-agent %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return err
+	}
+	return nil
+}
+
+// lintStyle returns the annotation style for the given lint report.
+func lintStyle(r *atlasexec.SummaryReport) string {
+	switch {
+	case len(r.Errors()) > 0:
+		return "error"
+	case r.DiagnosticsCount() > 0:
+		return "warning"
+	default:
+		return "success"
+	}
+}
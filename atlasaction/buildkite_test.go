@@ -0,0 +1,124 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/sqlclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildkite_GetInput(t *testing.T) {
+	env := map[string]string{
+		"ATLAS_INPUT_DIR":                 "file://migrations",
+		"BUILDKITE_PLUGIN_ATLAS_DIR":      "file://ignored",
+		"BUILDKITE_PLUGIN_ATLAS_DEV_URL":  "sqlite://dev?mode=memory",
+		"BUILDKITE_PLUGIN_ATLAS_SCHEMA_0": "public",
+		"BUILDKITE_PLUGIN_ATLAS_SCHEMA_1": "private",
+	}
+	a := atlasaction.NewBuildkite(func(k string) string { return env[k] }, &bytes.Buffer{})
+	require.Equal(t, "file://migrations", a.GetInput("dir"))
+	require.Equal(t, "sqlite://dev?mode=memory", a.GetInput("dev-url"))
+	require.Equal(t, "public\nprivate", a.GetInput("schema"))
+	require.Empty(t, a.GetInput("env"))
+}
+
+func TestBuildkite_GetTriggerContext(t *testing.T) {
+	env := map[string]string{
+		"BUILDKITE_BRANCH":                   "feature",
+		"BUILDKITE_COMMIT":                   "abc123",
+		"BUILDKITE_REPO":                     "git@github.com:ariga/atlas-action.git",
+		"BUILDKITE_PIPELINE_DEFAULT_BRANCH":  "main",
+		"BUILDKITE_PULL_REQUEST":             "false",
+		"BUILDKITE_PULL_REQUEST_BASE_BRANCH": "",
+		"BUILDKITE_BUILD_CREATOR":            "a8m",
+	}
+	a := atlasaction.NewBuildkite(func(k string) string { return env[k] }, &bytes.Buffer{})
+	tc, err := a.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, tc.SCMClient)
+	tc.SCMClient = nil
+	require.Equal(t, &atlasaction.TriggerContext{
+		Act:           a,
+		SCMType:       atlasexec.SCMTypeGithub,
+		Repo:          "ariga/atlas-action",
+		RepoURL:       "https://github.com/ariga/atlas-action",
+		DefaultBranch: "main",
+		Branch:        "feature",
+		Commit:        "abc123",
+		Actor:         &atlasaction.Actor{Name: "a8m"},
+	}, tc)
+
+	env["BUILDKITE_PULL_REQUEST"] = "42"
+	env["BUILDKITE_PULL_REQUEST_BASE_BRANCH"] = "develop"
+	tc, err = a.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "develop", tc.DefaultBranch)
+	require.Equal(t, &atlasaction.PullRequest{
+		Number: 42,
+		URL:    "https://github.com/ariga/atlas-action/pull/42",
+		Commit: "abc123",
+	}, tc.PullRequest)
+
+	// Pull requests on hosts that cannot be probed are reported when the client is used.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	env["BUILDKITE_REPO"] = srv.URL + "/ariga/atlas-action.git"
+	tc, err = a.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, tc.SCMType)
+	require.Equal(t, 42, tc.PullRequest.Number)
+	_, err = tc.SCMClient()
+	require.EqualError(t, err, "unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+
+	env["BUILDKITE_PULL_REQUEST"] = "main"
+	_, err = a.GetTriggerContext(context.Background())
+	require.ErrorContains(t, err, "failed to parse BUILDKITE_PULL_REQUEST")
+}
+
+func TestBuildkite_Agent(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	// Record the arguments and the stdin of each call.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "buildkite-agent"), []byte(`#!/bin/sh
+echo "$@" >> `+calls+`
+cat >> `+calls+`
+echo >> `+calls+`
+`), 0700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	env := map[string]string{
+		"ATLAS_ACTION_COMMAND": "migrate/apply",
+	}
+	var out bytes.Buffer
+	a := atlasaction.NewBuildkite(func(k string) string { return env[k] }, &out)
+	a.SetOutput("current", "20240101000000")
+	a.MigrateApply(context.Background(), &atlasexec.MigrateApply{
+		Env: atlasexec.Env{
+			Driver: "sqlite",
+			Dir:    "migrations",
+			URL:    &sqlclient.URL{URL: &url.URL{Scheme: "sqlite", Host: "file"}},
+		},
+		Error: "migration failed",
+	})
+	require.Empty(t, out.String())
+	buf, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	lines := strings.SplitN(string(buf), "\n", 4)
+	require.Equal(t, "meta-data set ATLAS_OUTPUT_MIGRATE_APPLY_CURRENT", lines[0])
+	require.Equal(t, "20240101000000", lines[1])
+	require.Equal(t, "annotate --style error --context atlas-migrate-apply", lines[2])
+	require.Contains(t, lines[3], "Migration Failed")
+}
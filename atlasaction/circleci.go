@@ -8,6 +8,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"ariga.io/atlas/atlasexec"
@@ -19,7 +23,11 @@ type CircleCI struct {
 	getenv func(string) string
 }
 
-var _ Action = (*CircleCI)(nil)
+var (
+	_ Action       = (*CircleCI)(nil)
+	_ Reporter     = (*CircleCI)(nil)
+	_ TestReporter = (*CircleCI)(nil)
+)
 
 // NewCircleCI returns a new CircleCI.
 func NewCircleCI(getenv func(string) string, w io.Writer) *CircleCI {
@@ -74,9 +82,9 @@ func (a *CircleCI) GetTriggerContext(ctx context.Context) (*TriggerContext, erro
 	if tc.Commit == "" {
 		return nil, fmt.Errorf("missing CIRCLE_SHA1 environment variable")
 	}
-	// Detect SCM provider based on Token.
+	// Detect SCM provider based on Token or GitHub App credentials.
 	switch ghToken := a.getenv("GITHUB_TOKEN"); {
-	case ghToken != "":
+	case ghToken != "" || hasGitHubApp(a.getenv):
 		// Used to change the location that the linting results are posted to.
 		// If GITHUB_REPOSITORY is not set, we default to the CIRCLE_PROJECT_REPONAME repo.
 		if v := a.getenv("GITHUB_REPOSITORY"); v != "" {
@@ -97,7 +105,7 @@ func (a *CircleCI) GetTriggerContext(ctx context.Context) (*TriggerContext, erro
 			tc.Branch = tag
 			return tc, nil
 		}
-		c, err := NewGitHubClient(tc.Repo, a.getenv("GITHUB_API_URL"), ghToken)
+		c, err := newGitHubClient(a.getenv, tc.Repo, a.getenv("GITHUB_API_URL"), ghToken)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create GitHub client: %w", err)
 		}
@@ -113,6 +121,138 @@ func (a *CircleCI) GetTriggerContext(ctx context.Context) (*TriggerContext, erro
 				Commit: pr.Commit,
 			}
 		}
+	case a.getenv("CIRCLE_PULL_REQUEST") != "":
+		// Projects hosted on GitLab or Bitbucket, including self-managed
+		// instances, are detected from the URL of the pull request.
+		repoURL, repo, number, err := parsePullRequestURL(a.getenv("CIRCLE_PULL_REQUEST"))
+		if err != nil {
+			return nil, err
+		}
+		// CIRCLE_REPOSITORY_URL is usually an SCP-like SSH address (git@host:repo.git).
+		if u, err := url.Parse(tc.RepoURL); err != nil || u.Host == "" {
+			tc.RepoURL = repoURL
+		}
+		tc.Repo, tc.SCMType = repo, "" // Set by detectSCM.
+		tc.PullRequest = &PullRequest{Number: number, Commit: tc.Commit}
+		if err := tc.detectSCM(ctx); err != nil {
+			return nil, err
+		}
 	}
 	return tc, nil
 }
+
+// parsePullRequestURL parses the web URL of a pull request, as set in CIRCLE_PULL_REQUEST,
+// and returns the URL and the path of its repository, and its number. For example:
+//
+//	https://github.com/ariga/atlas/pull/1
+//	https://gitlab.com/ariga/atlas/-/merge_requests/1
+//	https://bitbucket.org/ariga/atlas/pull-requests/1
+func parsePullRequestURL(s string) (string, string, int, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("parsing pull request URL %q: %w", s, err)
+	}
+	for _, sep := range []string{"/-/merge_requests/", "/pull-requests/", "/pull/"} {
+		if repo, n, ok := strings.Cut(u.Path, sep); ok {
+			number, err := strconv.Atoi(strings.Trim(n, "/"))
+			if err != nil {
+				return "", "", 0, fmt.Errorf("invalid pull request number in URL %q", s)
+			}
+			u.Path, u.RawQuery, u.Fragment = repo, "", ""
+			return u.String(), strings.Trim(repo, "/"), number, nil
+		}
+	}
+	return "", "", 0, fmt.Errorf("unexpected pull request URL %q", s)
+}
+
+// The CircleCI reporter writes the lint and test results as JUnit XML files to the
+// test results directory, and the apply reports to the artifacts directory. Both
+// can be configured using ATLAS_TEST_RESULTS_DIR and ATLAS_ARTIFACTS_DIR:
+//
+//	- store_test_results:
+//	    path: /tmp/atlas-action/test-results
+//	- store_artifacts:
+//	    path: /tmp/atlas-action/artifacts
+//
+// https://circleci.com/docs/collect-test-data/
+
+// MigrateApply implements Reporter.
+func (a *CircleCI) MigrateApply(_ context.Context, r *atlasexec.MigrateApply) {
+	a.applyReport("migrate-apply", r)
+}
+
+// MigrateLint implements Reporter.
+func (a *CircleCI) MigrateLint(_ context.Context, r *atlasexec.SummaryReport) {
+	dir := filepath.Join(a.GetInput("working-directory"), r.Env.Dir)
+	a.writeJUnit(CmdMigrateLint, lintJUnit(CmdMigrateLint, dir, r))
+}
+
+// SchemaApply implements Reporter.
+func (a *CircleCI) SchemaApply(_ context.Context, r *atlasexec.SchemaApply) {
+	a.applyReport("schema-apply", r)
+}
+
+// SchemaPlan implements Reporter.
+func (a *CircleCI) SchemaPlan(_ context.Context, r *atlasexec.SchemaPlan) {
+	if l := r.Lint; l != nil {
+		dir := filepath.Join(a.GetInput("working-directory"), l.Env.Dir)
+		a.writeJUnit(CmdSchemaPlan, lintJUnit(CmdSchemaPlan, dir, l))
+	}
+}
+
+// SchemaLint implements Reporter.
+func (a *CircleCI) SchemaLint(_ context.Context, r *SchemaLintReport) {
+	a.writeJUnit(CmdSchemaLint, schemaLintJUnit(CmdSchemaLint, r))
+}
+
+// TestResults implements TestReporter.
+func (a *CircleCI) TestResults(_ context.Context, r *TestReport) {
+	a.writeJUnit(r.Cmd, testJUnit(r))
+}
+
+// writeJUnit appends the suite to the JUnit file of the given action,
+// and exposes its path in the "junit-report" output.
+func (a *CircleCI) writeJUnit(cmd string, suite *junitTestSuite) {
+	path := filepath.Join(a.outputDir("ATLAS_TEST_RESULTS_DIR", "test-results"),
+		strings.ReplaceAll(cmd, "/", "-")+".xml")
+	if err := appendJUnit(path, suite); err != nil {
+		a.Errorf("failed to write JUnit report: %v", err)
+		return
+	}
+	a.SetOutput("junit-report", path)
+}
+
+// applyReport writes the apply report as a markdown artifact,
+// and exposes its path in the "report-file" output.
+func (a *CircleCI) applyReport(name string, data any) {
+	summary, err := RenderTemplate(name+".tmpl", data, nil)
+	if err != nil {
+		a.Errorf("failed to create summary: %v", err)
+		return
+	}
+	path := filepath.Join(a.outputDir("ATLAS_ARTIFACTS_DIR", "artifacts"), name+".md")
+	// Multiple runs (e.g. multi-tenant deployments)
+	// are appended to the same report.
+	_, err = os.Stat(path)
+	exists := err == nil
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		a.Errorf("failed to create artifacts directory: %v", err)
+		return
+	}
+	if err := fprintln(path, summary); err != nil {
+		a.Errorf("failed to write report %s: %v", path, err)
+		return
+	}
+	if !exists {
+		a.SetOutput("report-file", path)
+	}
+}
+
+// outputDir returns the directory set in the given environment variable,
+// or the default one under the atlas-action temporary directory.
+func (a *CircleCI) outputDir(env, name string) string {
+	if dir := a.getenv(env); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "atlas-action", name)
+}
@@ -5,18 +5,29 @@
 package atlasaction_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"ariga.io/atlas-action/atlasaction"
 	"ariga.io/atlas/atlasexec"
 	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
 	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/stretchr/testify/require"
 )
@@ -63,6 +74,172 @@ func Test_circleCIOrb_GetTriggerContext(t *testing.T) {
 	}, ctx.PullRequest)
 }
 
+func Test_circleCIOrb_GitHubApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := http.NewServeMux()
+	m.HandleFunc("POST /app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_token","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	m.HandleFunc("GET /repos/ariga/atlas-orb/pulls", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer ghs_token", r.Header.Get("Authorization"))
+		w.Write([]byte(`[{"number":1,"url":"https://api.github.com/repos/ariga/atlas-orb/pulls/1","head":{"sha":"1234567890"}}]`))
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	env := map[string]string{
+		"CIRCLE_PROJECT_REPONAME":    "atlas-orb",
+		"CIRCLE_SHA1":                "1234567890",
+		"CIRCLE_BRANCH":              "main",
+		"GITHUB_REPOSITORY":          "ariga/atlas-orb",
+		"GITHUB_API_URL":             srv.URL,
+		"GITHUB_APP_ID":              "1234",
+		"GITHUB_APP_INSTALLATION_ID": "7",
+		// Single-line secrets have their line breaks escaped.
+		"GITHUB_APP_PRIVATE_KEY": strings.ReplaceAll(string(pemKey), "\n", `\n`),
+	}
+	orb := atlasaction.NewCircleCI(func(k string) string { return env[k] }, &bytes.Buffer{})
+	tc, err := orb.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, tc.PullRequest.Number)
+
+	env["GITHUB_APP_INSTALLATION_ID"] = ""
+	_, err = orb.GetTriggerContext(context.Background())
+	require.ErrorContains(t, err, "invalid GITHUB_APP_INSTALLATION_ID")
+}
+
+func Test_circleCIOrb_GitHubChecks(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var checks int
+	m := http.NewServeMux()
+	m.HandleFunc("POST /app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_token","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	m.HandleFunc("GET /repos/ariga/atlas-orb/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"number":1,"url":"https://api.github.com/repos/ariga/atlas-orb/pulls/1","head":{"sha":"1234567890"}}]`))
+	})
+	m.HandleFunc("GET /repos/ariga/atlas-orb/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	m.HandleFunc("POST /repos/ariga/atlas-orb/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	m.HandleFunc("POST /repos/ariga/atlas-orb/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer ghs_token", r.Header.Get("Authorization"))
+		checks++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	})
+	m.HandleFunc("PATCH /repos/ariga/atlas-orb/check-runs/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"status":"completed"}`))
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	env := map[string]string{
+		"CIRCLE_PROJECT_REPONAME": "atlas-orb",
+		"CIRCLE_SHA1":             "1234567890",
+		"CIRCLE_BRANCH":           "feature",
+		"GITHUB_REPOSITORY":       "ariga/atlas-orb",
+		"GITHUB_API_URL":          srv.URL,
+		"GITHUB_TOKEN":            "token",
+		"ATLAS_TEST_RESULTS_DIR":  t.TempDir(),
+		"ATLAS_INPUT_URL":         "file://schema.hcl",
+	}
+	run := func() {
+		a, err := atlasaction.New(
+			atlasaction.WithAction(atlasaction.NewCircleCI(func(k string) string { return env[k] }, &bytes.Buffer{})),
+			atlasaction.WithAtlas(&mockAtlas{
+				schemaLint: func(context.Context, *atlasexec.SchemaLintParams) (*atlasexec.SchemaLintReport, error) {
+					return &atlasexec.SchemaLintReport{}, nil
+				},
+			}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, a.SchemaLint(context.Background()))
+	}
+	// The Check Runs API is not available to personal access tokens.
+	run()
+	require.Zero(t, checks)
+
+	env["GITHUB_APP_ID"] = "1234"
+	env["GITHUB_APP_INSTALLATION_ID"] = "7"
+	env["GITHUB_APP_PRIVATE_KEY"] = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	run()
+	require.Equal(t, 1, checks)
+}
+
+func Test_circleCIOrb_PullRequestURL(t *testing.T) {
+	for _, tt := range []struct {
+		pr, repoURL, repo string
+		scm               atlasexec.SCMType
+	}{
+		{
+			pr:      "https://gitlab.com/ariga/db/atlas/-/merge_requests/12",
+			repoURL: "https://gitlab.com/ariga/db/atlas",
+			repo:    "ariga/db/atlas",
+			scm:     atlasexec.SCMTypeGitlab,
+		},
+		{
+			pr:      "https://bitbucket.org/ariga/atlas/pull-requests/12",
+			repoURL: "https://bitbucket.org/ariga/atlas",
+			repo:    "ariga/atlas",
+			scm:     atlasexec.SCMTypeBitbucket,
+		},
+		{
+			pr:      "https://github.com/ariga/atlas/pull/12",
+			repoURL: "https://github.com/ariga/atlas",
+			repo:    "ariga/atlas",
+			scm:     atlasexec.SCMTypeGithub,
+		},
+	} {
+		env := map[string]string{
+			"CIRCLE_PROJECT_REPONAME": "atlas",
+			"CIRCLE_REPOSITORY_URL":   "git@gitlab.com:ariga/atlas.git",
+			"CIRCLE_SHA1":             "1234567890",
+			"CIRCLE_PULL_REQUEST":     tt.pr,
+		}
+		orb := atlasaction.NewCircleCI(func(k string) string { return env[k] }, &bytes.Buffer{})
+		tc, err := orb.GetTriggerContext(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, tt.scm, tc.SCMType)
+		require.Equal(t, tt.repo, tc.Repo)
+		require.Equal(t, tt.repoURL, tc.RepoURL)
+		require.Equal(t, &atlasaction.PullRequest{Number: 12, URL: tt.pr, Commit: "1234567890"}, tc.PullRequest)
+		require.NotNil(t, tc.SCMClient)
+	}
+	// Pull requests on hosts that cannot be probed are reported when the client is used.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	orb := atlasaction.NewCircleCI(func(k string) string {
+		return map[string]string{
+			"CIRCLE_PROJECT_REPONAME": "atlas",
+			"CIRCLE_SHA1":             "1234567890",
+			"CIRCLE_PULL_REQUEST":     srv.URL + "/ariga/atlas/pull/12",
+		}[k]
+	}, &bytes.Buffer{})
+	tc, err := orb.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, tc.SCMType)
+	require.Equal(t, 12, tc.PullRequest.Number)
+	_, err = tc.SCMClient()
+	require.EqualError(t, err, "unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+
+	orb = atlasaction.NewCircleCI(func(k string) string {
+		return map[string]string{
+			"CIRCLE_PROJECT_REPONAME": "atlas",
+			"CIRCLE_SHA1":             "1234567890",
+			"CIRCLE_PULL_REQUEST":     "https://gitlab.com/ariga/atlas/-/merge_requests/main",
+		}[k]
+	}, &bytes.Buffer{})
+	_, err = orb.GetTriggerContext(context.Background())
+	require.EqualError(t, err, `invalid pull request number in URL "https://gitlab.com/ariga/atlas/-/merge_requests/main"`)
+}
+
 func TestCircleCI(t *testing.T) {
 	var (
 		actions = "actions"
@@ -108,6 +285,8 @@ func TestCircleCI(t *testing.T) {
 			e.Setenv("CIRCLECI", "true")
 			e.Setenv("GITHUB_API_URL", srv.URL)
 			e.Setenv("BASH_ENV", filepath.Join(dir, "output.sh"))
+			e.Setenv("ATLAS_TEST_RESULTS_DIR", "test-results")
+			e.Setenv("ATLAS_ARTIFACTS_DIR", "artifacts")
 			return nil
 		},
 		Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
@@ -164,3 +343,78 @@ func TestCircleCI(t *testing.T) {
 		},
 	})
 }
+
+func TestCircleCI_Reporter(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{
+		"BASH_ENV":               filepath.Join(dir, "output.sh"),
+		"ATLAS_TEST_RESULTS_DIR": filepath.Join(dir, "test-results"),
+		"ATLAS_ARTIFACTS_DIR":    filepath.Join(dir, "artifacts"),
+		"ATLAS_ACTION_COMMAND":   "migrate/lint",
+	}
+	a := atlasaction.NewCircleCI(func(k string) string { return env[k] }, &bytes.Buffer{})
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{
+			{
+				Name: "1.sql",
+				Text: "CREATE TABLE t(c int);\nDROP TABLE t;\n",
+				Reports: []sqlcheck.Report{{
+					Text: "destructive changes detected",
+					Diagnostics: []sqlcheck.Diagnostic{
+						{Pos: 23, Text: `Dropping table "t"`, Code: "DS102"},
+					},
+				}},
+				Error: "destructive changes detected",
+			},
+		},
+	}
+	lint.Env.Dir = "migrations"
+	a.MigrateLint(context.Background(), lint)
+	buf, err := os.ReadFile(filepath.Join(dir, "test-results", "migrate-lint.xml"))
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="1" failures="1" skipped="0">
+  <testsuite name="migrate/lint" tests="1" failures="1" skipped="0">
+    <testcase name="1.sql" classname="migrations">
+      <failure message="destructive changes detected" type="error">migrations/1.sql:2: destructive changes detected: Dropping table &#34;t&#34; (DS102)</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(buf))
+
+	env["ATLAS_ACTION_COMMAND"] = "migrate/test"
+	a.TestResults(context.Background(), atlasaction.NewTestReport(atlasaction.CmdMigrateTest, "", errors.New("migrations/1.sql:1: assertion failed")))
+	buf, err = os.ReadFile(filepath.Join(dir, "test-results", "migrate-test.xml"))
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="1" failures="1" skipped="0">
+  <testsuite name="migrate/test" tests="1" failures="1" skipped="0">
+    <testcase name="migrate/test" classname="migrate/test">
+      <failure message="migrate/test failed" type="error">migrations/1.sql:1: assertion failed</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(buf))
+
+	env["ATLAS_ACTION_COMMAND"] = "migrate/apply"
+	a.MigrateApply(context.Background(), &atlasexec.MigrateApply{
+		Env: atlasexec.Env{
+			Driver: "sqlite3",
+			URL:    &sqlclient.URL{URL: &url.URL{Scheme: "sqlite", Host: "file"}},
+			Dir:    "migrations",
+		},
+		Target: "20240101000000",
+	})
+	_, err = os.Stat(filepath.Join(dir, "artifacts", "migrate-apply.md"))
+	require.NoError(t, err)
+	buf, err = os.ReadFile(filepath.Join(dir, "output.sh"))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf(`export ATLAS_OUTPUT_MIGRATE_LINT_JUNIT_REPORT=%[1]q
+export ATLAS_OUTPUT_MIGRATE_TEST_JUNIT_REPORT=%[2]q
+export ATLAS_OUTPUT_MIGRATE_APPLY_REPORT_FILE=%[3]q
+`,
+		filepath.Join(dir, "test-results", "migrate-lint.xml"),
+		filepath.Join(dir, "test-results", "migrate-test.xml"),
+		filepath.Join(dir, "artifacts", "migrate-apply.md"),
+	), string(buf))
+}
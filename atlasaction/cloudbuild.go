@@ -0,0 +1,136 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+)
+
+// CloudBuild is an implementation of the Action interface for Google Cloud Build.
+//
+// Cloud Build does not expose the build substitutions as environment variables,
+// so they must be mapped in the step definition:
+//
+//	steps:
+//	  - name: arigaio/atlas-action
+//	    args: ["--action", "migrate/lint"]
+//	    env:
+//	      - PROJECT_ID=$PROJECT_ID
+//	      - BUILD_ID=$BUILD_ID
+//	      - COMMIT_SHA=$COMMIT_SHA
+//	      - BRANCH_NAME=$BRANCH_NAME
+//	      - REPO_FULL_NAME=$REPO_FULL_NAME
+//	      - _PR_NUMBER=$_PR_NUMBER
+//	      - _HEAD_BRANCH=$_HEAD_BRANCH
+//	      - _BASE_BRANCH=$_BASE_BRANCH
+//	      - _HEAD_REPO_URL=$_HEAD_REPO_URL
+type CloudBuild struct {
+	*coloredLogger
+	getenv func(string) string
+}
+
+var _ Action = (*CloudBuild)(nil)
+
+// NewCloudBuild returns a new CloudBuild.
+func NewCloudBuild(getenv func(string) string, w io.Writer) *CloudBuild {
+	return &CloudBuild{getenv: getenv, coloredLogger: &coloredLogger{w}}
+}
+
+// GetType implements the Action interface.
+func (a *CloudBuild) GetType() atlasexec.TriggerType {
+	return atlasexec.TriggerType("CLOUD_BUILD")
+}
+
+// Getenv implements Action.
+func (a *CloudBuild) Getenv(key string) string {
+	return a.getenv(key)
+}
+
+// GetInput implements the Action interface.
+func (a *CloudBuild) GetInput(name string) string {
+	return strings.TrimSpace(a.getenv(toInputVarName(name)))
+}
+
+// SetOutput implements the Action interface.
+func (a *CloudBuild) SetOutput(name, value string) {
+	// Each step runs in its own container, and only the /workspace
+	// directory is shared between them. So the outputs are written
+	// to a file that can be sourced by the next steps.
+	// e.g:
+	// ```shell
+	// source /workspace/.atlas-action/outputs.sh
+	// ```
+	dir := "/workspace/.atlas-action"
+	if out := a.getenv("ATLAS_OUTPUT_DIR"); out != "" {
+		// The user can set the output directory using
+		// the ATLAS_OUTPUT_DIR environment variable.
+		dir = out
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		a.Errorf("failed to create output directory %s: %v", dir, err)
+		return
+	}
+	outputs := filepath.Join(dir, "outputs.sh")
+	err := fprintln(outputs,
+		"export", toOutputVar(a.getenv("ATLAS_ACTION_COMMAND"), name, value))
+	if err != nil {
+		a.Errorf("failed to write output to file %s: %v", outputs, err)
+	}
+}
+
+// GetTriggerContext implements the Action interface.
+// https://cloud.google.com/build/docs/configuring-builds/substitute-variable-values
+func (a *CloudBuild) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc := &TriggerContext{
+		Act:    a,
+		Branch: a.getenv("BRANCH_NAME"),
+		Commit: a.getenv("COMMIT_SHA"),
+	}
+	if tc.Commit == "" {
+		return nil, fmt.Errorf("missing COMMIT_SHA environment variable")
+	}
+	if u := a.getenv("_HEAD_REPO_URL"); u != "" {
+		var err error
+		if tc.RepoURL, tc.Repo, err = parseRemoteURL(u); err != nil {
+			return nil, err
+		}
+	}
+	if r := a.getenv("REPO_FULL_NAME"); r != "" && r != tc.Repo {
+		// On pull requests from forks, the head repository is the fork,
+		// but the comments should be added to the base repository.
+		tc.Repo = r
+		if u, err := url.Parse(tc.RepoURL); err == nil && u.Host != "" {
+			u.Path = "/" + r
+			tc.RepoURL = u.String()
+		}
+	}
+	if pr := a.getenv("_PR_NUMBER"); pr != "" {
+		n, err := strconv.Atoi(pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse _PR_NUMBER: %w", err)
+		}
+		tc.PullRequest = &PullRequest{
+			Number: n,
+			Commit: tc.Commit,
+		}
+		if b := a.getenv("_HEAD_BRANCH"); b != "" {
+			tc.Branch = b
+		}
+		tc.DefaultBranch = a.getenv("_BASE_BRANCH")
+	}
+	if err := tc.detectSCM(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudBuild_GetTriggerContext(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *atlasaction.TriggerContext
+	}{
+		{
+			name: "branch",
+			env: map[string]string{
+				"COMMIT_SHA":     "abc123",
+				"BRANCH_NAME":    "main",
+				"_HEAD_REPO_URL": "https://gitlab.com/ariga/atlas-action",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType: atlasexec.SCMTypeGitlab,
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://gitlab.com/ariga/atlas-action",
+				Branch:  "main",
+				Commit:  "abc123",
+			},
+		},
+		{
+			name: "pull request from fork",
+			env: map[string]string{
+				"COMMIT_SHA":     "abc123",
+				"REPO_FULL_NAME": "ariga/atlas-action",
+				"_PR_NUMBER":     "7",
+				"_HEAD_BRANCH":   "feature",
+				"_BASE_BRANCH":   "master",
+				"_HEAD_REPO_URL": "https://github.com/a8m/atlas-action.git",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType:       atlasexec.SCMTypeGithub,
+				Repo:          "ariga/atlas-action",
+				RepoURL:       "https://github.com/ariga/atlas-action",
+				Branch:        "feature",
+				DefaultBranch: "master",
+				Commit:        "abc123",
+				PullRequest: &atlasaction.PullRequest{
+					Number: 7,
+					URL:    "https://github.com/ariga/atlas-action/pull/7",
+					Commit: "abc123",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := atlasaction.NewCloudBuild(func(k string) string { return tt.env[k] }, &bytes.Buffer{})
+			tc, err := a.GetTriggerContext(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, tc.SCMClient)
+			tt.want.Act, tc.SCMClient = a, nil
+			require.Equal(t, tt.want, tc)
+		})
+	}
+	t.Run("unknown SCM pull request", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		env := map[string]string{
+			"COMMIT_SHA":     "abc123",
+			"_PR_NUMBER":     "7",
+			"_HEAD_REPO_URL": srv.URL + "/ariga/atlas-action.git",
+		}
+		a := atlasaction.NewCloudBuild(func(k string) string { return env[k] }, &bytes.Buffer{})
+		tc, err := a.GetTriggerContext(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, tc.SCMType)
+		require.Equal(t, 7, tc.PullRequest.Number)
+		_, err = tc.SCMClient()
+		require.EqualError(t, err, "unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+	})
+}
+
+func TestCloudBuild_SetOutput(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{
+		"ATLAS_OUTPUT_DIR":     dir,
+		"ATLAS_ACTION_COMMAND": "schema/plan",
+	}
+	a := atlasaction.NewCloudBuild(func(k string) string { return env[k] }, &bytes.Buffer{})
+	a.SetOutput("link", "https://example.com/plans/1")
+	buf, err := os.ReadFile(filepath.Join(dir, "outputs.sh"))
+	require.NoError(t, err)
+	require.Equal(t, "export ATLAS_OUTPUT_SCHEMA_PLAN_LINK=\"https://example.com/plans/1\"\n", string(buf))
+}
@@ -0,0 +1,126 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+)
+
+// CodeBuild is an implementation of the Action interface for AWS CodeBuild.
+type CodeBuild struct {
+	*coloredLogger
+	getenv func(string) string
+}
+
+var _ Action = (*CodeBuild)(nil)
+
+// NewCodeBuild returns a new CodeBuild.
+func NewCodeBuild(getenv func(string) string, w io.Writer) *CodeBuild {
+	return &CodeBuild{getenv: getenv, coloredLogger: &coloredLogger{w}}
+}
+
+// GetType implements the Action interface.
+func (a *CodeBuild) GetType() atlasexec.TriggerType {
+	return atlasexec.TriggerType("AWS_CODEBUILD")
+}
+
+// Getenv implements Action.
+func (a *CodeBuild) Getenv(key string) string {
+	return a.getenv(key)
+}
+
+// GetInput implements the Action interface.
+func (a *CodeBuild) GetInput(name string) string {
+	return strings.TrimSpace(a.getenv(toInputVarName(name)))
+}
+
+// SetOutput implements the Action interface.
+func (a *CodeBuild) SetOutput(name, value string) {
+	// The action runs in a child process, so it cannot set variables in the
+	// build environment directly. Instead, the outputs are written to a file
+	// that can be sourced and exported by the buildspec:
+	// ```yaml
+	// env:
+	//   exported-variables:
+	//     - ATLAS_OUTPUT_MIGRATE_APPLY_CURRENT
+	// phases:
+	//   build:
+	//     commands:
+	//       - atlas-action --action migrate/apply
+	//       - . .atlas-action/outputs.sh
+	// ```
+	path := filepath.Join(a.getenv("CODEBUILD_SRC_DIR"), ".atlas-action", "outputs.sh")
+	if out := a.getenv("ATLAS_OUTPUT_FILE"); out != "" {
+		// The user can set the output file using
+		// the ATLAS_OUTPUT_FILE environment variable.
+		path = out
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		a.Errorf("failed to create output directory %s: %v", filepath.Dir(path), err)
+		return
+	}
+	err := fprintln(path,
+		"export", toOutputVar(a.getenv("ATLAS_ACTION_COMMAND"), name, value))
+	if err != nil {
+		a.Errorf("failed to write output to file %s: %v", path, err)
+	}
+}
+
+// reCommitSHA matches a full SHA-1 or SHA-256 commit hash.
+var reCommitSHA = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// GetTriggerContext implements the Action interface.
+// https://docs.aws.amazon.com/codebuild/latest/userguide/build-env-ref-env-vars.html
+func (a *CodeBuild) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc := &TriggerContext{
+		Act:    a,
+		Commit: a.getenv("CODEBUILD_RESOLVED_SOURCE_VERSION"),
+	}
+	// CODEBUILD_SOURCE_VERSION may also hold a pull request (pr/1) or a branch name.
+	if v := a.getenv("CODEBUILD_SOURCE_VERSION"); tc.Commit == "" && reCommitSHA.MatchString(v) {
+		tc.Commit = v
+	}
+	if tc.Commit == "" {
+		return nil, fmt.Errorf("missing CODEBUILD_RESOLVED_SOURCE_VERSION environment variable")
+	}
+	if u := a.getenv("CODEBUILD_SOURCE_REPO_URL"); u != "" {
+		var err error
+		if tc.RepoURL, tc.Repo, err = parseRemoteURL(u); err != nil {
+			return nil, err
+		}
+	}
+	if id := a.getenv("CODEBUILD_WEBHOOK_ACTOR_ACCOUNT_ID"); id != "" {
+		tc.Actor = &Actor{ID: id}
+	}
+	// The webhook trigger is one of: pr/<number>, branch/<name> or tag/<name>.
+	switch kind, v, _ := strings.Cut(a.getenv("CODEBUILD_WEBHOOK_TRIGGER"), "/"); kind {
+	case "pr":
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CODEBUILD_WEBHOOK_TRIGGER: %w", err)
+		}
+		tc.PullRequest = &PullRequest{
+			Number: n,
+			Commit: tc.Commit,
+		}
+		tc.Branch = strings.TrimPrefix(a.getenv("CODEBUILD_WEBHOOK_HEAD_REF"), "refs/heads/")
+		tc.DefaultBranch = strings.TrimPrefix(a.getenv("CODEBUILD_WEBHOOK_BASE_REF"), "refs/heads/")
+	case "branch", "tag":
+		tc.Branch = v
+	}
+	if err := tc.detectSCM(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeBuild_GetTriggerContext(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *atlasaction.TriggerContext
+	}{
+		{
+			name: "pull request",
+			env: map[string]string{
+				"CODEBUILD_RESOLVED_SOURCE_VERSION":  "abc123",
+				"CODEBUILD_SOURCE_REPO_URL":          "https://github.com/ariga/atlas-action.git",
+				"CODEBUILD_WEBHOOK_TRIGGER":          "pr/123",
+				"CODEBUILD_WEBHOOK_HEAD_REF":         "refs/heads/feature",
+				"CODEBUILD_WEBHOOK_BASE_REF":         "refs/heads/main",
+				"CODEBUILD_WEBHOOK_ACTOR_ACCOUNT_ID": "42",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType:       atlasexec.SCMTypeGithub,
+				Repo:          "ariga/atlas-action",
+				RepoURL:       "https://github.com/ariga/atlas-action",
+				DefaultBranch: "main",
+				Branch:        "feature",
+				Commit:        "abc123",
+				Actor:         &atlasaction.Actor{ID: "42"},
+				PullRequest: &atlasaction.PullRequest{
+					Number: 123,
+					URL:    "https://github.com/ariga/atlas-action/pull/123",
+					Commit: "abc123",
+				},
+			},
+		},
+		{
+			name: "branch",
+			env: map[string]string{
+				"CODEBUILD_RESOLVED_SOURCE_VERSION": "abc123",
+				"CODEBUILD_SOURCE_REPO_URL":         "https://bitbucket.org/ariga/atlas-action.git",
+				"CODEBUILD_WEBHOOK_TRIGGER":         "branch/main",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType: atlasexec.SCMTypeBitbucket,
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://bitbucket.org/ariga/atlas-action",
+				Branch:  "main",
+				Commit:  "abc123",
+			},
+		},
+		{
+			name: "manual build",
+			env: map[string]string{
+				"CODEBUILD_SOURCE_VERSION":  "0123456789abcdef0123456789abcdef01234567",
+				"CODEBUILD_SOURCE_REPO_URL": "https://gitlab.com/ariga/atlas-action.git",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType: atlasexec.SCMTypeGitlab,
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://gitlab.com/ariga/atlas-action",
+				Commit:  "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := atlasaction.NewCodeBuild(func(k string) string { return tt.env[k] }, &bytes.Buffer{})
+			tc, err := a.GetTriggerContext(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, tc.SCMClient)
+			tt.want.Act, tc.SCMClient = a, nil
+			require.Equal(t, tt.want, tc)
+		})
+	}
+	t.Run("source version is not a commit", func(t *testing.T) {
+		for _, v := range []string{"pr/1", "main"} {
+			env := map[string]string{"CODEBUILD_SOURCE_VERSION": v}
+			a := atlasaction.NewCodeBuild(func(k string) string { return env[k] }, &bytes.Buffer{})
+			_, err := a.GetTriggerContext(context.Background())
+			require.EqualError(t, err, "missing CODEBUILD_RESOLVED_SOURCE_VERSION environment variable")
+		}
+	})
+	t.Run("unknown SCM pull request", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		env := map[string]string{
+			"CODEBUILD_RESOLVED_SOURCE_VERSION": "abc123",
+			"CODEBUILD_SOURCE_REPO_URL":         srv.URL + "/ariga/atlas-action.git",
+			"CODEBUILD_WEBHOOK_TRIGGER":         "pr/123",
+		}
+		a := atlasaction.NewCodeBuild(func(k string) string { return env[k] }, &bytes.Buffer{})
+		tc, err := a.GetTriggerContext(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, tc.SCMType)
+		require.Equal(t, 123, tc.PullRequest.Number)
+		_, err = tc.SCMClient()
+		require.EqualError(t, err, "unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+	})
+	t.Run("invalid trigger", func(t *testing.T) {
+		env := map[string]string{
+			"CODEBUILD_RESOLVED_SOURCE_VERSION": "abc123",
+			"CODEBUILD_WEBHOOK_TRIGGER":         "pr/main",
+		}
+		a := atlasaction.NewCodeBuild(func(k string) string { return env[k] }, &bytes.Buffer{})
+		_, err := a.GetTriggerContext(context.Background())
+		require.ErrorContains(t, err, "failed to parse CODEBUILD_WEBHOOK_TRIGGER")
+	})
+}
+
+func TestCodeBuild_SetOutput(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{
+		"CODEBUILD_SRC_DIR":    dir,
+		"ATLAS_ACTION_COMMAND": "migrate/apply",
+	}
+	a := atlasaction.NewCodeBuild(func(k string) string { return env[k] }, &bytes.Buffer{})
+	a.SetOutput("current", "20240101000000")
+	a.SetOutput("target", "20240102000000")
+	buf, err := os.ReadFile(filepath.Join(dir, ".atlas-action", "outputs.sh"))
+	require.NoError(t, err)
+	require.Equal(t, `export ATLAS_OUTPUT_MIGRATE_APPLY_CURRENT="20240101000000"
+export ATLAS_OUTPUT_MIGRATE_APPLY_TARGET="20240102000000"
+`, string(buf))
+}
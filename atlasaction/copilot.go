@@ -78,7 +78,7 @@ If you have any questions or want me to edit the test, you can comment on this P
 			),
 		})
 	// Atlas Copilot will react to comments that start with /atlas.
-	case tc.Comment != nil && strings.ToLower(tc.Comment.Body[:6]) == "/atlas":
+	case tc.Comment != nil && strings.HasPrefix(strings.ToLower(tc.Comment.Body), "/atlas"):
 		// We need to know the PR's head branch to commit the changes.
 		pr, err := c.PullRequest(ctx, tc.Comment.Number)
 		if err != nil {
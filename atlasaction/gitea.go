@@ -0,0 +1,218 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"ariga.io/atlas-action/internal/gitea"
+	"ariga.io/atlas/atlasexec"
+)
+
+// Gitea is an implementation of the Action interface for Gitea and Forgejo Actions.
+// Gitea Actions are compatible with GitHub Actions, so most of the
+// implementation is shared, except for the SCM client.
+type Gitea struct {
+	*GitHub
+}
+
+// NewGitea returns a new Action for Gitea Actions.
+func NewGitea(getenv func(string) string, w io.Writer) *Gitea {
+	return &Gitea{GitHub: NewGitHub(getenv, w)}
+}
+
+// GetType implements the Action interface.
+func (*Gitea) GetType() atlasexec.TriggerType {
+	return atlasexec.TriggerType("GITEA_ACTIONS")
+}
+
+// GetTriggerContext implements the Action interface.
+func (a *Gitea) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc, err := a.GitHub.GetTriggerContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Atlas Cloud has no SCM type for Gitea. The repository is reported as
+	// GitHub, as the Gitea API is modeled after it, and the client is set below.
+	tc.Act, tc.RerunCmd = a, ""
+	tc.SCMClient = func() (SCMClient, error) {
+		token := a.Getenv("GITEA_TOKEN")
+		if token == "" {
+			// Gitea Actions provides the token using both names.
+			token = a.Getenv("GITHUB_TOKEN")
+		}
+		if token == "" {
+			a.Warningf("GITEA_TOKEN is not set, the action may not have all the permissions")
+		}
+		return NewGiteaClient(tc.Repo, a.apiURL(), token)
+	}
+	return tc, nil
+}
+
+// apiURL returns the URL of the Gitea API.
+func (a *Gitea) apiURL() string {
+	if u := a.Getenv("GITHUB_API_URL"); u != "" {
+		return u
+	}
+	if u := a.Getenv("GITHUB_SERVER_URL"); u != "" {
+		return strings.TrimSuffix(u, "/") + "/api/v1"
+	}
+	return ""
+}
+
+// GiteaClient is an implementation of the SCMClient interface for Gitea.
+type GiteaClient struct {
+	*gitea.Client
+}
+
+// NewGiteaClient returns a new Gitea client for the given repository.
+func NewGiteaClient(repo, baseURL, token string) (*GiteaClient, error) {
+	c, err := gitea.NewClient(repo,
+		gitea.WithBaseURL(baseURL),
+		gitea.WithToken(token),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &GiteaClient{Client: c}, nil
+}
+
+// PullRequest implements SCMClient.
+func (c *GiteaClient) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	pr, err := c.Client.PullRequest(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return convertGiteaPullRequest(pr), nil
+}
+
+// CreatePullRequest implements SCMClient.
+func (c *GiteaClient) CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	pr, err := c.Client.CreatePullRequest(ctx, head, base, title, body)
+	if err != nil {
+		return nil, err
+	}
+	return convertGiteaPullRequest(pr), nil
+}
+
+// CopilotSession implements SCMClient.
+func (c *GiteaClient) CopilotSession(ctx context.Context, tc *TriggerContext) (string, error) {
+	cs, err := c.IssueComments(ctx, func() int {
+		if tc.PullRequest != nil {
+			return tc.PullRequest.Number
+		}
+		return tc.Comment.Number
+	}())
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cs {
+		if m := reCopilotSession.FindStringSubmatch(c.Body); len(m) > 1 {
+			return m[1], nil
+		}
+	}
+	return "", nil
+}
+
+// CommentCopilot implements SCMClient.
+func (c *GiteaClient) CommentCopilot(ctx context.Context, pr int, cp *Copilot) error {
+	var buf strings.Builder
+	if cp.Prompt != "" {
+		fmt.Fprintf(&buf, "> %s\n\n", cp.Prompt)
+	}
+	fmt.Fprintf(&buf, copilotSession, cp.Response, cp.Session)
+	return c.CreateIssueComment(ctx, pr, buf.String())
+}
+
+// CommentLint implements SCMClient.
+func (c *GiteaClient) CommentLint(ctx context.Context, tc *TriggerContext, r *atlasexec.SummaryReport) error {
+	comment, err := RenderTemplate("migrate-lint.tmpl", r, tc)
+	if err != nil {
+		return err
+	}
+	return c.upsertComment(ctx, tc.PullRequest, tc.Act.GetInput("dir-name"), comment)
+}
+
+// CommentPlan implements SCMClient.
+func (c *GiteaClient) CommentPlan(ctx context.Context, tc *TriggerContext, p *atlasexec.SchemaPlan) error {
+	// Report the schema plan to the user and add a comment to the PR.
+	comment, err := RenderTemplate("schema-plan.tmpl", map[string]any{
+		"Plan":         p,
+		"RerunCommand": tc.RerunCmd,
+	}, tc)
+	if err != nil {
+		return err
+	}
+	return c.upsertComment(ctx, tc.PullRequest, p.File.Name, comment)
+}
+
+// CommentSchemaLint implements SCMClient.
+func (c *GiteaClient) CommentSchemaLint(ctx context.Context, tc *TriggerContext, r *SchemaLintReport) error {
+	id := schemaLintCommentID(tc)
+	if len(r.Steps) == 0 {
+		return c.deleteComment(ctx, tc.PullRequest, id)
+	}
+	comment, err := RenderTemplate("schema-lint.tmpl", r, tc)
+	if err != nil {
+		return err
+	}
+	return c.upsertComment(ctx, tc.PullRequest, id, comment)
+}
+
+func (c *GiteaClient) upsertComment(ctx context.Context, pr *PullRequest, id, comment string) error {
+	if pr == nil {
+		return fmt.Errorf("pull request is required for commenting")
+	}
+	comments, err := c.IssueComments(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+	marker := commentMarker(id)
+	comment += "\n" + marker
+	if found := slices.IndexFunc(comments, func(c gitea.IssueComment) bool {
+		return strings.Contains(c.Body, marker)
+	}); found != -1 {
+		return c.UpdateIssueComment(ctx, comments[found].ID, comment)
+	}
+	return c.CreateIssueComment(ctx, pr.Number, comment)
+}
+
+func (c *GiteaClient) deleteComment(ctx context.Context, pr *PullRequest, id string) error {
+	if pr == nil {
+		return fmt.Errorf("pull request is required for commenting")
+	}
+	comments, err := c.IssueComments(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+	marker := commentMarker(id)
+	if found := slices.IndexFunc(comments, func(c gitea.IssueComment) bool {
+		return strings.Contains(c.Body, marker)
+	}); found != -1 {
+		return c.DeleteIssueComment(ctx, comments[found].ID)
+	}
+	return nil
+}
+
+func convertGiteaPullRequest(pr *gitea.PullRequest) *PullRequest {
+	if pr == nil {
+		return nil
+	}
+	return &PullRequest{
+		Number: pr.Number,
+		URL:    pr.URL,
+		Body:   pr.Body,
+		Commit: pr.Commit,
+		Ref:    pr.Ref,
+	}
+}
+
+var _ Action = (*Gitea)(nil)
+var _ Reporter = (*Gitea)(nil)
+var _ SCMClient = (*GiteaClient)(nil)
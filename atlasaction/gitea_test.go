@@ -0,0 +1,121 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitea(t *testing.T) {
+	type comment struct {
+		ID   int    `json:"id"`
+		Body string `json:"body"`
+	}
+	var (
+		comments []*comment
+		mux      = http.NewServeMux()
+	)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/issues/{num}/comments", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "ariga/atlas", r.PathValue("owner")+"/"+r.PathValue("repo"))
+		require.Equal(t, "token secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewEncoder(w).Encode(comments))
+	})
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/issues/{num}/comments", func(w http.ResponseWriter, r *http.Request) {
+		var c comment
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&c))
+		c.ID = len(comments) + 1
+		comments = append(comments, &c)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("PATCH /api/v1/repos/{owner}/{repo}/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		require.NoError(t, err)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(comments[id-1]))
+	})
+	mux.HandleFunc("DELETE /api/v1/repos/{owner}/{repo}/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		require.NoError(t, err)
+		comments[id-1].Body = ""
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	event := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(event, []byte(`{
+  "pull_request": {
+    "number": 1,
+    "html_url": "`+srv.URL+`/ariga/atlas/pulls/1",
+    "head": {"sha": "abc123"}
+  },
+  "repository": {
+    "html_url": "`+srv.URL+`/ariga/atlas",
+    "default_branch": "main"
+  }
+}`), 0600))
+	env := map[string]string{
+		"GITEA_ACTIONS":     "true",
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_EVENT_NAME": "pull_request",
+		"GITHUB_EVENT_PATH": event,
+		"GITHUB_REPOSITORY": "ariga/atlas",
+		"GITHUB_HEAD_REF":   "feature",
+		"GITHUB_SHA":        "abc123",
+		"GITHUB_SERVER_URL": srv.URL,
+		"GITEA_TOKEN":       "secret",
+	}
+	act, err := atlasaction.New(
+		atlasaction.WithGetenv(func(k string) string { return env[k] }),
+		atlasaction.WithOut(&bytes.Buffer{}),
+		atlasaction.WithAtlas(&mockAtlas{}),
+	)
+	require.NoError(t, err)
+	require.IsType(t, (*atlasaction.Gitea)(nil), act.Action)
+
+	ctx := context.Background()
+	tc, err := act.GetTriggerContext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, atlasexec.SCMTypeGithub, tc.SCMType)
+	require.Equal(t, "ariga/atlas", tc.Repo)
+	require.Equal(t, srv.URL+"/ariga/atlas", tc.RepoURL)
+	require.Equal(t, "main", tc.DefaultBranch)
+	require.Equal(t, "feature", tc.Branch)
+	require.Equal(t, &atlasaction.PullRequest{
+		Number: 1,
+		URL:    srv.URL + "/ariga/atlas/pulls/1",
+		Commit: "abc123",
+	}, tc.PullRequest)
+
+	c, err := tc.SCMClient()
+	require.NoError(t, err)
+	report := &atlasaction.SchemaLintReport{
+		SchemaLintReport: &atlasexec.SchemaLintReport{
+			Steps: []atlasexec.Report{{Text: "destructive change detected"}},
+		},
+	}
+	// Create the comment, and then update it in place.
+	require.NoError(t, c.CommentSchemaLint(ctx, tc, report))
+	require.Len(t, comments, 1)
+	require.NoError(t, c.CommentSchemaLint(ctx, tc, report))
+	require.Len(t, comments, 1)
+	require.True(t, strings.HasSuffix(comments[0].Body, "<!-- generated by ariga/atlas-action for schema-lint -->"))
+	// Delete the comment once all issues are fixed.
+	report.Steps = nil
+	require.NoError(t, c.CommentSchemaLint(ctx, tc, report))
+	require.Empty(t, comments[0].Body)
+}
@@ -115,7 +115,7 @@ func (a *GitHub) GetTriggerContext(context.Context) (*TriggerContext, error) {
 		SCMType: atlasexec.SCMTypeGithub,
 		SCMClient: func() (SCMClient, error) {
 			token := a.Getenv("GITHUB_TOKEN")
-			if token == "" {
+			if token == "" && !hasGitHubApp(a.Getenv) {
 				a.Warningf("GITHUB_TOKEN is not set, the action may not have all the permissions")
 				if os.Getenv("GITHUB_ACTIONS") != "" {
 					a.Warningf("On GitHub Actions, you can set the token in the workflow file:")
@@ -133,7 +133,7 @@ func (a *GitHub) GetTriggerContext(context.Context) (*TriggerContext, error) {
 					a.Warningf("  ```")
 				}
 			}
-			return NewGitHubClient(ctx.Repository, ctx.APIURL, token)
+			return newGitHubClient(a.Getenv, ctx.Repository, ctx.APIURL, token)
 		},
 		Repo:          ctx.Repository,
 		Branch:        ctx.HeadRef,
@@ -248,6 +248,42 @@ func NewGitHubClient(repo, baseURL, token string) (*GitHubClient, error) {
 	return &GitHubClient{Client: c}, nil
 }
 
+// NewGitHubAppClient returns a new GitHub client that is authenticated as an installation of a GitHub App.
+func NewGitHubAppClient(repo, baseURL, appID string, installationID int64, privateKey []byte) (*GitHubClient, error) {
+	c, err := github.NewClient(repo,
+		github.WithBaseURL(baseURL),
+		github.WithAppCredentials(appID, installationID, privateKey),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubClient{Client: c}, nil
+}
+
+// hasGitHubApp reports if the GitHub App credentials are set in the environment.
+func hasGitHubApp(getenv func(string) string) bool {
+	return getenv("GITHUB_APP_ID") != ""
+}
+
+// newGitHubClient returns a GitHub client authenticated with the GitHub App credentials
+// set in the GITHUB_APP_* environment variables, or with the given token otherwise.
+func newGitHubClient(getenv func(string) string, repo, baseURL, token string) (*GitHubClient, error) {
+	if !hasGitHubApp(getenv) {
+		return NewGitHubClient(repo, baseURL, token)
+	}
+	installationID, err := strconv.ParseInt(getenv("GITHUB_APP_INSTALLATION_ID"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GITHUB_APP_INSTALLATION_ID: %w", err)
+	}
+	key := getenv("GITHUB_APP_PRIVATE_KEY")
+	if key == "" {
+		return nil, fmt.Errorf("GITHUB_APP_PRIVATE_KEY is required when GITHUB_APP_ID is set")
+	}
+	// Keys stored in single-line secrets have their line breaks escaped.
+	key = strings.ReplaceAll(key, `\n`, "\n")
+	return NewGitHubAppClient(repo, baseURL, getenv("GITHUB_APP_ID"), installationID, []byte(key))
+}
+
 // PullRequest implements SCMClient.
 func (c *GitHubClient) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
 	pr, err := c.Client.PullRequest(ctx, number)
@@ -313,13 +349,20 @@ func (c *GitHubClient) CommentLint(ctx context.Context, tc *TriggerContext, r *a
 	case err != nil:
 		tc.Act.Errorf("failed to list pull request files: %v", err)
 	default:
-		err = addSuggestions(tc.Act.GetInput("working-directory"), r, func(s *Suggestion) error {
+		var (
+			cw = tc.Act.GetInput("working-directory")
+			ss []*Suggestion
+		)
+		err = addSuggestions(cw, r, func(s *Suggestion) error {
 			// Add suggestion only if the file is part of the pull request.
 			if slices.Contains(files, s.Path) {
-				return c.upsertSuggestion(ctx, tc.PullRequest, s)
+				ss = append(ss, s)
 			}
 			return nil
 		})
+		if err == nil {
+			err = c.upsertSuggestions(ctx, tc.PullRequest, filepath.Join(cw, r.Env.Dir), ss)
+		}
 		if err != nil {
 			tc.Act.Errorf("failed to add suggestion on the pull request: %v", err)
 		}
@@ -353,6 +396,182 @@ func (c *GitHubClient) CommentSchemaLint(ctx context.Context, tc *TriggerContext
 	return c.upsertComment(ctx, tc.PullRequest, id, comment)
 }
 
+// checkRunName is the name of the checks published by the action.
+const checkRunName = "Atlas"
+
+// CheckLint implements SCMChecker.
+func (c *GitHubClient) CheckLint(ctx context.Context, tc *TriggerContext, r *atlasexec.SummaryReport) error {
+	anns, errs, warns := lintAnnotations(tc.Act.GetInput("working-directory"), r)
+	out := &github.CheckRunOutput{
+		Title:       "Migrate Lint",
+		Summary:     lintSummary(errs, warns),
+		Annotations: anns,
+	}
+	out.Text, _ = RenderTemplate("migrate-lint.tmpl", r, tc)
+	return c.publishCheck(ctx, tc, lintConclusion(errs, warns), out)
+}
+
+// CheckPlan implements SCMChecker.
+func (c *GitHubClient) CheckPlan(ctx context.Context, tc *TriggerContext, p *atlasexec.SchemaPlan) error {
+	out := &github.CheckRunOutput{
+		Title:   "Schema Plan",
+		Summary: fmt.Sprintf("Schema plan `%s` was created.", p.File.Name),
+	}
+	conclusion := "success"
+	if p.Lint != nil {
+		var errs, warns int
+		out.Annotations, errs, warns = lintAnnotations(tc.Act.GetInput("working-directory"), p.Lint)
+		out.Summary += " " + lintSummary(errs, warns)
+		conclusion = lintConclusion(errs, warns)
+	}
+	out.Text, _ = RenderTemplate("schema-plan.tmpl", map[string]any{
+		"Plan":         p,
+		"RerunCommand": tc.RerunCmd,
+	}, tc)
+	return c.publishCheck(ctx, tc, conclusion, out)
+}
+
+// CheckSchemaLint implements SCMChecker.
+func (c *GitHubClient) CheckSchemaLint(ctx context.Context, tc *TriggerContext, r *SchemaLintReport) error {
+	var (
+		errs, warns int
+		anns        []*github.CheckRunAnnotation
+		wd          = tc.Act.GetInput("working-directory")
+	)
+	for _, step := range r.Steps {
+		level := "warning"
+		if step.Error {
+			level = "failure"
+		}
+		for _, d := range step.Diagnostics {
+			if step.Error {
+				errs++
+			} else {
+				warns++
+			}
+			// Annotations require a file, the step is listed in the text.
+			if d.Pos == nil {
+				continue
+			}
+			path := d.Pos.Filename
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(wd, path)
+			}
+			line := max(1, d.Pos.Start.Line)
+			anns = append(anns, &github.CheckRunAnnotation{
+				Path:      filepath.ToSlash(path),
+				StartLine: line,
+				EndLine:   line,
+				Level:     level,
+				Title:     step.Text,
+				Message:   checkMessage(d.Text, d.Code),
+			})
+		}
+	}
+	out := &github.CheckRunOutput{
+		Title:       "Schema Lint",
+		Summary:     lintSummary(errs, warns),
+		Annotations: anns,
+	}
+	if len(r.Steps) > 0 {
+		out.Text, _ = RenderTemplate("schema-lint.tmpl", r, tc)
+	}
+	return c.publishCheck(ctx, tc, lintConclusion(errs, warns), out)
+}
+
+// publishCheck creates a check run on the pull request commit and completes it with the given output.
+func (c *GitHubClient) publishCheck(ctx context.Context, tc *TriggerContext, conclusion string, out *github.CheckRunOutput) error {
+	sha := tc.PullRequest.Commit
+	if sha == "" {
+		sha = tc.Commit
+	}
+	// GitHub limits the text of the check output to 65535 characters.
+	if len(out.Text) > 65535 {
+		out.Text = ""
+	}
+	run, err := c.CreateCheckRun(ctx, &github.CheckRun{
+		Name:    checkRunName,
+		HeadSHA: sha,
+		Status:  "in_progress",
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.CompleteCheckRun(ctx, run.ID, conclusion, out)
+	return err
+}
+
+// lintAnnotations returns the check annotations for the given lint report,
+// along with the number of errors and warnings.
+func lintAnnotations(wd string, r *atlasexec.SummaryReport) (anns []*github.CheckRunAnnotation, errs, warns int) {
+	dir := filepath.Join(wd, r.Env.Dir)
+	for _, f := range r.Files {
+		path := filepath.ToSlash(filepath.Join(dir, f.Name))
+		if f.Error != "" && len(f.Reports) == 0 {
+			errs++
+			anns = append(anns, &github.CheckRunAnnotation{
+				Path:      path,
+				StartLine: 1,
+				EndLine:   1,
+				Level:     "failure",
+				Message:   f.Error,
+			})
+			continue
+		}
+		level := "warning"
+		if f.Error != "" {
+			level = "failure"
+		}
+		for _, rr := range f.Reports {
+			for _, d := range rr.Diagnostics {
+				if f.Error != "" {
+					errs++
+				} else {
+					warns++
+				}
+				line := diagnosticLine(f, d.Pos)
+				anns = append(anns, &github.CheckRunAnnotation{
+					Path:      path,
+					StartLine: line,
+					EndLine:   line,
+					Level:     level,
+					Title:     rr.Text,
+					Message:   checkMessage(d.Text, d.Code),
+				})
+			}
+		}
+	}
+	return anns, errs, warns
+}
+
+// checkMessage returns the annotation message for the given diagnostic.
+func checkMessage(text, code string) string {
+	if code == "" {
+		return text
+	}
+	return fmt.Sprintf("%v (%v)\n\nDetails: https://atlasgo.io/lint/analyzers#%v", text, code, code)
+}
+
+// lintSummary returns the summary of the check for the given number of issues.
+func lintSummary(errs, warns int) string {
+	if errs+warns == 0 {
+		return "No issues found."
+	}
+	return fmt.Sprintf("Atlas found %d error(s) and %d warning(s).", errs, warns)
+}
+
+// lintConclusion returns the conclusion of the check for the given number of issues.
+func lintConclusion(errs, warns int) string {
+	switch {
+	case errs > 0:
+		return "failure"
+	case warns > 0:
+		return "neutral"
+	default:
+		return "success"
+	}
+}
+
 func (c *GitHubClient) upsertComment(ctx context.Context, pr *PullRequest, id, comment string) error {
 	return c.comment(ctx, pr, id, comment, true)
 }
@@ -394,32 +613,63 @@ func (c *GitHubClient) comment(ctx context.Context, pr *PullRequest, id, comment
 	return err
 }
 
-func (c *GitHubClient) upsertSuggestion(ctx context.Context, pr *PullRequest, s *Suggestion) error {
-	// TODO: Listing the comments only once and updating the comment in the same call.
+// upsertSuggestions submits the new suggestions as a single pull request review, updates the changed
+// ones and deletes the suggestions on files of the given directory that no longer exist in the report.
+func (c *GitHubClient) upsertSuggestions(ctx context.Context, pr *PullRequest, dir string, ss []*Suggestion) error {
 	comments, err := c.ReviewComments(ctx, pr.Number)
 	if err != nil {
 		return err
 	}
 	var (
-		marker  = commentMarker(s.ID)
-		comment = s.Comment + "\n" + marker
+		keep   = make(map[int]bool)
+		review = &github.PullRequestReview{CommitID: pr.Commit, Event: "COMMENT"}
 	)
-	if found := slices.IndexFunc(comments, func(c github.PullRequestComment) bool {
-		return c.Path == s.Path && strings.Contains(c.Body, marker)
-	}); found != -1 {
-		err = c.UpdateReviewComment(ctx, comments[found].ID, comment)
-	} else {
-		err = c.CreateReviewComment(ctx, pr.Number, &github.PullRequestComment{
-			CommitID:  pr.Commit,
-			Body:      comment,
-			Path:      s.Path,
-			Line:      s.Line,
-			StartLine: s.StartLine,
+	for _, s := range ss {
+		var (
+			marker  = commentMarker(s.ID)
+			comment = s.Comment + "\n" + marker
+		)
+		found := slices.IndexFunc(comments, func(c github.PullRequestComment) bool {
+			return c.Path == s.Path && strings.Contains(c.Body, marker)
 		})
+		if found == -1 {
+			review.Comments = append(review.Comments, &github.PullRequestComment{
+				Body:      comment,
+				Path:      s.Path,
+				Line:      s.Line,
+				StartLine: s.StartLine,
+			})
+			continue
+		}
+		keep[comments[found].ID] = true
+		if comments[found].Body != comment {
+			if err := c.UpdateReviewComment(ctx, comments[found].ID, comment); err != nil {
+				return err
+			}
+		}
 	}
-	return err
+	if len(review.Comments) > 0 {
+		if err := c.CreateReview(ctx, pr.Number, review); err != nil {
+			return err
+		}
+	}
+	prefix := filepath.ToSlash(filepath.Clean(dir)) + "/"
+	if prefix == "./" {
+		prefix = ""
+	}
+	for _, rc := range comments {
+		if !keep[rc.ID] && strings.HasPrefix(rc.Path, prefix) && reCommentMarker.MatchString(rc.Body) {
+			if err := c.DeleteReviewComment(ctx, rc.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
+// reCommentMarker matches the marker of comments generated by the action.
+var reCommentMarker = regexp.MustCompile(`<!-- generated by ariga/atlas-action for .+ -->`)
+
 type Suggestion struct {
 	ID        string // Suggestion ID.
 	Path      string // File path.
@@ -509,3 +759,4 @@ func convertPullRequest(pr *github.PullRequest) *PullRequest {
 var _ Action = (*GitHub)(nil)
 var _ Reporter = (*GitHub)(nil)
 var _ SCMClient = (*GitHubClient)(nil)
+var _ SCMChecker = (*GitHubClient)(nil)
@@ -0,0 +1,186 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas-action/internal/github"
+	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubClient_CheckLint(t *testing.T) {
+	var (
+		created *github.CheckRun
+		updated []*github.CheckRun
+	)
+	m := http.NewServeMux()
+	m.HandleFunc("POST /repos/ariga/atlas/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":7}`))
+	})
+	m.HandleFunc("PATCH /repos/ariga/atlas/check-runs/7", func(w http.ResponseWriter, r *http.Request) {
+		var run github.CheckRun
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&run))
+		updated = append(updated, &run)
+		w.Write([]byte(`{"id":7}`))
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	c, err := atlasaction.NewGitHubClient("ariga/atlas", srv.URL, "token")
+	require.NoError(t, err)
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{
+			{
+				Name: "1.sql",
+				Text: "CREATE TABLE t(c int);\nDROP TABLE t;\n",
+				Reports: []sqlcheck.Report{{
+					Text: "destructive changes detected",
+					Diagnostics: []sqlcheck.Diagnostic{
+						{Pos: 23, Text: `Dropping table "t"`, Code: "DS102"},
+					},
+				}},
+				Error: "destructive changes detected",
+			},
+		},
+	}
+	lint.Env.Dir = "migrations"
+	lint.Env.URL = &sqlclient.URL{URL: &url.URL{Scheme: "sqlite", Host: "file"}}
+	tc := &atlasaction.TriggerContext{
+		Act:         &mockAction{inputs: map[string]string{"working-directory": "db"}},
+		Commit:      "main-sha",
+		PullRequest: &atlasaction.PullRequest{Number: 1, Commit: "pr-sha"},
+	}
+	require.NoError(t, c.CheckLint(context.Background(), tc, lint))
+	require.Equal(t, &github.CheckRun{Name: "Atlas", HeadSHA: "pr-sha", Status: "in_progress"}, created)
+	require.Len(t, updated, 1)
+	require.Equal(t, "completed", updated[0].Status)
+	require.Equal(t, "failure", updated[0].Conclusion)
+	require.Equal(t, "Migrate Lint", updated[0].Output.Title)
+	require.Equal(t, "Atlas found 1 error(s) and 0 warning(s).", updated[0].Output.Summary)
+	require.Equal(t, []*github.CheckRunAnnotation{{
+		Path:      "db/migrations/1.sql",
+		StartLine: 2,
+		EndLine:   2,
+		Level:     "failure",
+		Title:     "destructive changes detected",
+		Message:   "Dropping table \"t\" (DS102)\n\nDetails: https://atlasgo.io/lint/analyzers#DS102",
+	}}, updated[0].Output.Annotations)
+}
+
+func TestGitHubClient_CommentLintSuggestions(t *testing.T) {
+	var (
+		reviews  []*github.PullRequestReview
+		comments = []github.PullRequestComment{
+			{ID: 1, Path: "db/migrations/2.sql", Body: "fixed\n<!-- generated by ariga/atlas-action for Add a default value -->"},
+			{ID: 2, Path: "other/1.sql", Body: "other\n<!-- generated by ariga/atlas-action for Add a default value -->"},
+			{ID: 3, Path: "db/migrations/2.sql", Body: "looks good to me"},
+		}
+		updated, deleted []string
+	)
+	m := http.NewServeMux()
+	m.HandleFunc("GET /repos/ariga/atlas/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	m.HandleFunc("POST /repos/ariga/atlas/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	m.HandleFunc("GET /repos/ariga/atlas/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"filename":"db/migrations/1.sql"}]`))
+	})
+	m.HandleFunc("GET /repos/ariga/atlas/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(comments))
+	})
+	m.HandleFunc("POST /repos/ariga/atlas/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		var review github.PullRequestReview
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		reviews = append(reviews, &review)
+		for _, c := range review.Comments {
+			comments = append(comments, github.PullRequestComment{ID: len(comments) + 1, Path: c.Path, Body: c.Body})
+		}
+	})
+	m.HandleFunc("PATCH /repos/ariga/atlas/pulls/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		updated = append(updated, r.PathValue("id"))
+	})
+	m.HandleFunc("DELETE /repos/ariga/atlas/pulls/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	c, err := atlasaction.NewGitHubClient("ariga/atlas", srv.URL, "token")
+	require.NoError(t, err)
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{{
+			Name: "1.sql",
+			Text: "CREATE TABLE t(c int);\nALTER TABLE t ADD COLUMN d int NOT NULL;\nALTER TABLE t ADD COLUMN e int NOT NULL;\n",
+			Reports: []sqlcheck.Report{{
+				Text: "data dependent changes detected",
+				Diagnostics: []sqlcheck.Diagnostic{
+					{
+						Pos:  23,
+						Text: `Adding a non-nullable "int" column "d"`,
+						Code: "MY101",
+						SuggestedFixes: []sqlcheck.SuggestedFix{{
+							Message:  "Add a default value",
+							TextEdit: &sqlcheck.TextEdit{Line: 2, End: 2, NewText: "ALTER TABLE t ADD COLUMN d int NOT NULL DEFAULT 0;"},
+						}},
+					},
+					{
+						Pos:  64,
+						Text: `Adding a non-nullable "int" column "e"`,
+						Code: "MY101",
+						SuggestedFixes: []sqlcheck.SuggestedFix{{
+							Message:  "Add a default value to column e",
+							TextEdit: &sqlcheck.TextEdit{Line: 3, End: 3, NewText: "ALTER TABLE t ADD COLUMN e int NOT NULL DEFAULT 0;"},
+						}},
+					},
+				},
+			}},
+		}},
+	}
+	lint.Env.Dir = "migrations"
+	tc := &atlasaction.TriggerContext{
+		Act:         &mockAction{inputs: map[string]string{"working-directory": "db"}},
+		PullRequest: &atlasaction.PullRequest{Number: 1, Commit: "pr-sha"},
+	}
+	require.NoError(t, c.CommentLint(context.Background(), tc, lint))
+	// All new suggestions are submitted in a single review.
+	require.Len(t, reviews, 1)
+	require.Equal(t, "pr-sha", reviews[0].CommitID)
+	require.Equal(t, "COMMENT", reviews[0].Event)
+	require.Len(t, reviews[0].Comments, 2)
+	require.Equal(t, "db/migrations/1.sql", reviews[0].Comments[0].Path)
+	require.Equal(t, 2, reviews[0].Comments[0].Line)
+	require.Equal(t, 3, reviews[0].Comments[1].Line)
+	require.Contains(t, reviews[0].Comments[1].Body, "<!-- generated by ariga/atlas-action for Add a default value to column e -->")
+	// Suggestions that no longer exist in the directory are deleted.
+	require.Equal(t, []string{"1"}, deleted)
+	require.Empty(t, updated)
+
+	// Unchanged suggestions are not re-posted.
+	comments = slices.DeleteFunc(comments, func(c github.PullRequestComment) bool { return c.ID == 1 })
+	require.NoError(t, c.CommentLint(context.Background(), tc, lint))
+	require.Len(t, reviews, 1)
+	require.Empty(t, updated)
+
+	// Changed suggestions are updated in place.
+	lint.Files[0].Reports[0].Diagnostics[0].SuggestedFixes[0].TextEdit.NewText = "ALTER TABLE t ADD COLUMN d int NOT NULL DEFAULT 1;"
+	require.NoError(t, c.CommentLint(context.Background(), tc, lint))
+	require.Len(t, reviews, 1)
+	require.Equal(t, []string{"4"}, updated)
+	require.Equal(t, []string{"1"}, deleted)
+}
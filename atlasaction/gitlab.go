@@ -6,8 +6,11 @@ package atlasaction
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
@@ -104,9 +107,256 @@ func (a *GitLab) GetTriggerContext(context.Context) (*TriggerContext, error) {
 			Body:   a.getenv("CI_MERGE_REQUEST_DESCRIPTION"),
 		}
 	}
+	// Pipelines triggered by a note webhook expose its payload in the TRIGGER_PAYLOAD file.
+	if p := a.getenv("TRIGGER_PAYLOAD"); p != "" && a.getenv("CI_PIPELINE_SOURCE") == "trigger" {
+		c, err := gitlabNoteComment(p)
+		if err != nil {
+			return nil, err
+		}
+		ctx.Comment = c
+	}
 	return ctx, nil
 }
 
+// gitlabNoteComment returns the comment of the note event in the given payload file,
+// or nil if the payload is not a note on a merge request.
+func gitlabNoteComment(path string) (*Comment, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trigger payload: %w", err)
+	}
+	var ev struct {
+		Kind  string `json:"object_kind"`
+		Attrs struct {
+			Note         string `json:"note"`
+			NoteableType string `json:"noteable_type"`
+			URL          string `json:"url"`
+		} `json:"object_attributes"`
+		MergeRequest *struct {
+			IID int `json:"iid"`
+		} `json:"merge_request"`
+	}
+	if err := json.Unmarshal(buf, &ev); err != nil {
+		return nil, fmt.Errorf("failed to parse trigger payload: %w", err)
+	}
+	if ev.Kind != "note" || ev.Attrs.NoteableType != "MergeRequest" || ev.MergeRequest == nil {
+		return nil, nil
+	}
+	return &Comment{
+		Number: ev.MergeRequest.IID,
+		URL:    ev.Attrs.URL,
+		Body:   ev.Attrs.Note,
+	}, nil
+}
+
+// The files below are written to the artifacts directory, and are expected
+// to be declared in the job definition, so GitLab can show them in the MR.
+// Their names are suffixed with the job ID (see jobArtifact), as the artifacts
+// directory is downloaded by jobs of later stages:
+//
+//	artifacts:
+//	  reports:
+//	    codequality: .atlas-action/gl-code-quality-report-*.json
+//	    junit: .atlas-action/junit-*.xml
+//	  expose_as: Atlas Report
+//	  paths:
+//	    - .atlas-action/
+const (
+	gitlabCodeQualityFile = "gl-code-quality-report.json"
+	gitlabJUnitFile       = "junit.xml"
+)
+
+// MigrateApply implements Reporter.
+func (a *GitLab) MigrateApply(_ context.Context, r *atlasexec.MigrateApply) {
+	a.applyReport("migrate-apply", r)
+}
+
+// MigrateLint implements Reporter.
+func (a *GitLab) MigrateLint(_ context.Context, r *atlasexec.SummaryReport) {
+	a.lintReport(CmdMigrateLint, r)
+}
+
+// SchemaApply implements Reporter.
+func (a *GitLab) SchemaApply(_ context.Context, r *atlasexec.SchemaApply) {
+	a.applyReport("schema-apply", r)
+}
+
+// SchemaPlan implements Reporter.
+func (a *GitLab) SchemaPlan(_ context.Context, r *atlasexec.SchemaPlan) {
+	if l := r.Lint; l != nil {
+		a.lintReport(CmdSchemaPlan, l)
+	}
+}
+
+// SchemaLint implements Reporter.
+func (a *GitLab) SchemaLint(_ context.Context, r *SchemaLintReport) {
+	wd := a.GetInput("working-directory")
+	issues := make([]*gitlabCodeQualityIssue, 0, len(r.Steps))
+	for _, s := range r.Steps {
+		for _, d := range s.Diagnostics {
+			// Diagnostics without position cannot be shown in the MR diff.
+			if d.Pos == nil {
+				continue
+			}
+			path := d.Pos.Filename
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(wd, path)
+			}
+			issues = append(issues, a.codeQualityIssue(path, max(1, d.Pos.Start.Line), s.Text, d.Text, d.Code, s.Error))
+		}
+	}
+	if err := a.writeCodeQuality(issues); err != nil {
+		a.Errorf("failed to write code quality report: %v", err)
+	}
+	if err := appendJUnit(a.jobArtifact(gitlabJUnitFile), schemaLintJUnit(CmdSchemaLint, r)); err != nil {
+		a.Errorf("failed to write JUnit report: %v", err)
+	}
+}
+
+// lintReport writes the Code Quality and JUnit reports for the given lint report.
+func (a *GitLab) lintReport(name string, r *atlasexec.SummaryReport) {
+	dir := filepath.Join(a.GetInput("working-directory"), r.Env.Dir)
+	var issues []*gitlabCodeQualityIssue
+	for _, f := range r.Files {
+		path := filepath.Join(dir, f.Name)
+		if f.Error != "" && len(f.Reports) == 0 {
+			issues = append(issues, a.codeQualityIssue(path, 1, "", f.Error, "", true))
+			continue
+		}
+		for _, rr := range f.Reports {
+			for _, d := range rr.Diagnostics {
+				issues = append(issues, a.codeQualityIssue(path, diagnosticLine(f, d.Pos), rr.Text, d.Text, d.Code, f.Error != ""))
+			}
+		}
+	}
+	if err := a.writeCodeQuality(issues); err != nil {
+		a.Errorf("failed to write code quality report: %v", err)
+	}
+	if err := appendJUnit(a.jobArtifact(gitlabJUnitFile), lintJUnit(name, dir, r)); err != nil {
+		a.Errorf("failed to write JUnit report: %v", err)
+	}
+}
+
+// applyReport writes the apply report as a markdown artifact,
+// and exposes its path in the "report-file" output.
+func (a *GitLab) applyReport(name string, data any) {
+	summary, err := RenderTemplate(name+".tmpl", data, nil)
+	if err != nil {
+		a.Errorf("failed to create summary: %v", err)
+		return
+	}
+	path := a.artifact(name + ".md")
+	// Multiple runs (e.g. multi-tenant deployments)
+	// are appended to the same report.
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		a.Errorf("failed to create artifacts directory: %v", err)
+		return
+	}
+	if err := fprintln(path, summary); err != nil {
+		a.Errorf("failed to write report %s: %v", path, err)
+		return
+	}
+	a.SetOutput("report-file", a.relPath(path))
+}
+
+// gitlabCodeQualityIssue is a single issue in the GitLab Code Quality report.
+// https://docs.gitlab.com/ci/testing/code_quality/#code-quality-report-format
+type gitlabCodeQualityIssue struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+		} `json:"lines"`
+	} `json:"location"`
+}
+
+// codeQualityIssue returns a new Code Quality issue for the given diagnostic.
+func (a *GitLab) codeQualityIssue(path string, line int, title, text, code string, isErr bool) *gitlabCodeQualityIssue {
+	i := &gitlabCodeQualityIssue{
+		Description: diagnosticText(text, code),
+		CheckName:   code,
+		Severity:    "minor",
+	}
+	if title != "" {
+		i.Description = fmt.Sprintf("%s: %s", title, i.Description)
+	}
+	if i.CheckName == "" {
+		i.CheckName = "atlas"
+	}
+	if isErr {
+		i.Severity = "critical"
+	}
+	// Paths in the report must be relative to the project root.
+	i.Location.Path = filepath.ToSlash(a.relPath(path))
+	i.Location.Lines.Begin = line
+	// The fingerprint identifies the issue across pipelines.
+	i.Fingerprint, _ = hash(i.Location.Path, strconv.Itoa(line), i.CheckName, i.Description)
+	return i
+}
+
+// writeCodeQuality appends the issues to the Code Quality report.
+// The report is a single JSON array, shared by all actions in the job.
+func (a *GitLab) writeCodeQuality(issues []*gitlabCodeQualityIssue) error {
+	path := a.jobArtifact(gitlabCodeQualityFile)
+	var all []*gitlabCodeQualityIssue
+	switch buf, err := os.ReadFile(path); {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(buf, &all); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	// GitLab expects an empty array, not null, when there are no issues.
+	all = append(make([]*gitlabCodeQualityIssue, 0, len(all)+len(issues)), append(all, issues...)...)
+	buf, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0644)
+}
+
+// relPath returns the given path relative to the project root, if it is under it.
+func (a *GitLab) relPath(path string) string {
+	root := a.getenv("CI_PROJECT_DIR")
+	if root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// artifact returns the path of the given artifact file. By default, artifacts are written to
+// the .atlas-action directory in the project root, unless ATLAS_ARTIFACTS_DIR is set.
+func (a *GitLab) artifact(name string) string {
+	dir := a.getenv("ATLAS_ARTIFACTS_DIR")
+	if dir == "" {
+		dir = filepath.Join(a.getenv("CI_PROJECT_DIR"), ".atlas-action")
+	}
+	return filepath.Join(dir, name)
+}
+
+// jobArtifact returns the path of an artifact file owned by the current job. The job ID
+// is added to the file name, so reports downloaded from jobs of earlier stages are not
+// appended to, and published again.
+func (a *GitLab) jobArtifact(name string) string {
+	if id := a.getenv("CI_JOB_ID"); id != "" {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, ext), id, ext)
+	}
+	return a.artifact(name)
+}
+
 type GitLabClient struct {
 	*gitlab.Client
 }
@@ -123,23 +373,50 @@ func NewGitLabClient(project, baseURL, token string) (*GitLabClient, error) {
 }
 
 // PullRequest implements SCMClient.
-func (c *GitLabClient) PullRequest(context.Context, int) (*PullRequest, error) {
-	panic("unimplemented: PullRequest for GitLabClient")
+func (c *GitLabClient) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	mr, err := c.MergeRequest(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return convertGitLabMergeRequest(mr), nil
 }
 
 // CreatePullRequest implements SCMClient.
-func (c *GitLabClient) CreatePullRequest(_ context.Context, _, _, _, _ string) (*PullRequest, error) {
-	panic("unimplemented: CreatePullRequest for GitLabClient")
+func (c *GitLabClient) CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	mr, err := c.CreateMergeRequest(ctx, head, base, title, body)
+	if err != nil {
+		return nil, err
+	}
+	return convertGitLabMergeRequest(mr), nil
 }
 
 // CopilotSession implements SCMClient.
-func (c *GitLabClient) CopilotSession(context.Context, *TriggerContext) (string, error) {
-	panic("unimplemented: CopilotSession for GitLabClient")
+func (c *GitLabClient) CopilotSession(ctx context.Context, tc *TriggerContext) (string, error) {
+	notes, err := c.PullRequestNotes(ctx, func() int {
+		if tc.PullRequest != nil {
+			return tc.PullRequest.Number
+		}
+		return tc.Comment.Number
+	}())
+	if err != nil {
+		return "", err
+	}
+	for _, n := range notes {
+		if m := reCopilotSession.FindStringSubmatch(n.Body); !n.System && len(m) > 1 {
+			return m[1], nil
+		}
+	}
+	return "", nil
 }
 
 // CommentCopilot implements SCMClient.
-func (c *GitLabClient) CommentCopilot(context.Context, int, *Copilot) error {
-	panic("unimplemented: CommentCopilot for GitLabClient")
+func (c *GitLabClient) CommentCopilot(ctx context.Context, pr int, cp *Copilot) error {
+	var buf strings.Builder
+	if cp.Prompt != "" {
+		fmt.Fprintf(&buf, "> %s\n\n", cp.Prompt)
+	}
+	fmt.Fprintf(&buf, copilotSession, cp.Response, cp.Session)
+	return c.CreateNote(ctx, pr, buf.String())
 }
 
 // CommentLint implements SCMClient.
@@ -148,7 +425,24 @@ func (c *GitLabClient) CommentLint(ctx context.Context, tc *TriggerContext, r *a
 	if err != nil {
 		return err
 	}
-	return c.upsertComment(ctx, tc.PullRequest, tc.Act.GetInput("dir-name"), comment)
+	err = c.upsertComment(ctx, tc.PullRequest, tc.Act.GetInput("dir-name"), comment)
+	if err != nil {
+		return err
+	}
+	var ss []*Suggestion
+	if err = addSuggestions(tc.Act.GetInput("working-directory"), r, func(s *Suggestion) error {
+		ss = append(ss, s)
+		return nil
+	}); err != nil {
+		tc.Act.Errorf("failed to add suggestion on the merge request: %v", err)
+		return nil
+	}
+	if len(ss) > 0 {
+		if err = c.upsertSuggestions(ctx, tc.PullRequest, ss); err != nil {
+			tc.Act.Errorf("failed to add suggestion on the merge request: %v", err)
+		}
+	}
+	return nil
 }
 
 // CommentPlan implements SCMClient.
@@ -218,5 +512,87 @@ func (c *GitLabClient) comment(ctx context.Context, pr *PullRequest, id, comment
 	return c.CreateNote(ctx, pr.Number, comment)
 }
 
+// upsertSuggestions adds the suggestions as discussions on the merge request diff. Existing
+// discussions are updated in place, and suggestions for files outside the diff are skipped.
+func (c *GitLabClient) upsertSuggestions(ctx context.Context, pr *PullRequest, ss []*Suggestion) error {
+	mr, err := c.MergeRequest(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+	if mr.DiffRefs == nil {
+		return fmt.Errorf("merge request %d has no diff refs", pr.Number)
+	}
+	files, err := c.MergeRequestDiffPaths(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+	discussions, err := c.MergeRequestDiscussions(ctx, pr.Number)
+	if err != nil {
+		return err
+	}
+	for _, s := range ss {
+		path := filepath.ToSlash(s.Path)
+		// Add suggestion only if the file is part of the merge request.
+		if !slices.Contains(files, path) {
+			continue
+		}
+		line, lines := s.Line, 0
+		if s.StartLine > 0 {
+			line, lines = s.StartLine, s.Line-s.StartLine
+		}
+		var (
+			marker = commentMarker(s.ID)
+			// GitLab suggestions are anchored to the commented line,
+			// and span the given number of lines below it.
+			comment = strings.Replace(s.Comment, "```suggestion\n", fmt.Sprintf("```suggestion:-0+%d\n", lines), 1) + "\n" + marker
+		)
+		if n := gitlabDiffNote(discussions, path, marker); n != nil {
+			if n.Body != comment {
+				err = c.UpdateNote(ctx, pr.Number, n.ID, comment)
+			}
+		} else {
+			err = c.CreateDiscussion(ctx, pr.Number, comment, &gitlab.Position{
+				BaseSHA:      mr.DiffRefs.BaseSHA,
+				StartSHA:     mr.DiffRefs.StartSHA,
+				HeadSHA:      mr.DiffRefs.HeadSHA,
+				PositionType: "text",
+				NewPath:      path,
+				NewLine:      line,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitlabDiffNote returns the diff note on the given path that contains the marker, if exists.
+func gitlabDiffNote(discussions []gitlab.Discussion, path, marker string) *gitlab.Note {
+	for _, d := range discussions {
+		for i, n := range d.Notes {
+			if !n.System && n.Position != nil && n.Position.NewPath == path && strings.Contains(n.Body, marker) {
+				return &d.Notes[i]
+			}
+		}
+	}
+	return nil
+}
+
+// convertGitLabMergeRequest converts a GitLab merge request to a PullRequest.
+func convertGitLabMergeRequest(mr *gitlab.MergeRequest) *PullRequest {
+	if mr == nil {
+		return nil
+	}
+	return &PullRequest{
+		Number: mr.IID,
+		URL:    mr.WebURL,
+		Body:   mr.Description,
+		Commit: mr.SHA,
+		Ref:    mr.SourceBranch,
+	}
+}
+
 var _ Action = (*GitLab)(nil)
+var _ Reporter = (*GitLab)(nil)
 var _ SCMClient = (*GitLabClient)(nil)
@@ -1,15 +1,23 @@
 package atlasaction_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"testing"
 
+	"ariga.io/atlas-action/atlasaction"
 	"ariga.io/atlas-action/internal/gitlab"
+	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlclient"
 	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/stretchr/testify/require"
 )
@@ -119,3 +127,239 @@ func mockClientHandler(dir, token string) http.Handler {
 		m.ServeHTTP(w, r)
 	})
 }
+
+func TestGitlab_Reporter(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{
+		"CI_PROJECT_DIR":       dir,
+		"CI_JOB_ID":            "42",
+		"ATLAS_ACTION_COMMAND": "migrate/lint",
+	}
+	a := atlasaction.NewGitlab(func(k string) string { return env[k] }, &bytes.Buffer{})
+	// Reports downloaded from jobs of earlier stages are left untouched.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".atlas-action"), 0755))
+	earlier := filepath.Join(dir, ".atlas-action", "gl-code-quality-report-41.json")
+	require.NoError(t, os.WriteFile(earlier, []byte(`[{"check_name":"DS103"}]`), 0644))
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{
+			{
+				Name: "1.sql",
+				Text: "CREATE TABLE t(c int);\nDROP TABLE t;\n",
+				Reports: []sqlcheck.Report{{
+					Text: "destructive changes detected",
+					Diagnostics: []sqlcheck.Diagnostic{
+						{Pos: 23, Text: `Dropping table "t"`, Code: "DS102"},
+					},
+				}},
+				Error: "destructive changes detected",
+			},
+			{Name: "2.sql", Text: "CREATE TABLE t2(c int);\n"},
+		},
+	}
+	lint.Env.Dir = "migrations"
+	a.MigrateLint(context.Background(), lint)
+	var issues []map[string]any
+	buf, err := os.ReadFile(filepath.Join(dir, ".atlas-action", "gl-code-quality-report-42.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(buf, &issues))
+	require.Len(t, issues, 1)
+	require.Equal(t, "destructive changes detected: Dropping table \"t\" (DS102)", issues[0]["description"])
+	require.Equal(t, "DS102", issues[0]["check_name"])
+	require.Equal(t, "critical", issues[0]["severity"])
+	require.Equal(t, map[string]any{"path": "migrations/1.sql", "lines": map[string]any{"begin": float64(2)}}, issues[0]["location"])
+	require.NotEmpty(t, issues[0]["fingerprint"])
+
+	// Schema lint results are appended to the same reports.
+	pos := &schema.Pos{Filename: "schema.hcl"}
+	pos.Start.Line = 5
+	a.SchemaLint(context.Background(), &atlasaction.SchemaLintReport{
+		SchemaLintReport: &atlasexec.SchemaLintReport{
+			Steps: []atlasexec.Report{{
+				Text: "naming",
+				Diagnostics: []atlasexec.Diagnostic{{
+					Text: "Table name should use snake_case",
+					Code: "NM101",
+					Pos:  pos,
+				}},
+			}},
+		},
+	})
+	buf, err = os.ReadFile(filepath.Join(dir, ".atlas-action", "gl-code-quality-report-42.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(buf, &issues))
+	require.Len(t, issues, 2)
+	require.Equal(t, "minor", issues[1]["severity"])
+	buf, err = os.ReadFile(earlier)
+	require.NoError(t, err)
+	require.Equal(t, `[{"check_name":"DS103"}]`, string(buf))
+	require.Equal(t, map[string]any{"path": "schema.hcl", "lines": map[string]any{"begin": float64(5)}}, issues[1]["location"])
+	buf, err = os.ReadFile(filepath.Join(dir, ".atlas-action", "junit-42.xml"))
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="0">
+  <testsuite name="migrate/lint" tests="2" failures="1" skipped="0">
+    <testcase name="1.sql" classname="migrations">
+      <failure message="destructive changes detected" type="error">migrations/1.sql:2: destructive changes detected: Dropping table &#34;t&#34; (DS102)</failure>
+    </testcase>
+    <testcase name="2.sql" classname="migrations"></testcase>
+  </testsuite>
+  <testsuite name="schema/lint" tests="1" failures="0" skipped="0">
+    <testcase name="naming" classname="schema/lint">
+      <system-out>schema.hcl:5: Table name should use snake_case (NM101)</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(buf))
+
+	// The report file is exposed, even if it was downloaded from an earlier job.
+	env["ATLAS_ACTION_COMMAND"] = "migrate/apply"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".atlas-action", "migrate-apply.md"), nil, 0644))
+	a.MigrateApply(context.Background(), &atlasexec.MigrateApply{
+		Env: atlasexec.Env{
+			Driver: "sqlite3",
+			URL:    &sqlclient.URL{URL: &url.URL{Scheme: "sqlite", Host: "file"}},
+			Dir:    "migrations",
+		},
+		Target: "20240101000000",
+	})
+	_, err = os.Stat(filepath.Join(dir, ".atlas-action", "migrate-apply.md"))
+	require.NoError(t, err)
+	buf, err = os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	require.Equal(t, "ATLAS_OUTPUT_MIGRATE_APPLY_REPORT_FILE=\".atlas-action/migrate-apply.md\"\n", string(buf))
+}
+
+func TestGitlab_GetTriggerContext(t *testing.T) {
+	payload := filepath.Join(t.TempDir(), "payload.json")
+	require.NoError(t, os.WriteFile(payload, []byte(`{
+  "object_kind": "note",
+  "object_attributes": {"note": "/atlas add an index", "noteable_type": "MergeRequest", "url": "https://gitlab.com/ariga/atlas/-/merge_requests/2#note_1"},
+  "merge_request": {"iid": 2}
+}`), 0644))
+	env := map[string]string{
+		"CI_PIPELINE_SOURCE": "trigger",
+		"TRIGGER_PAYLOAD":    payload,
+	}
+	act := atlasaction.NewGitlab(func(k string) string { return env[k] }, &bytes.Buffer{})
+	tc, err := act.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, tc.PullRequest)
+	require.Equal(t, &atlasaction.Comment{
+		Number: 2,
+		URL:    "https://gitlab.com/ariga/atlas/-/merge_requests/2#note_1",
+		Body:   "/atlas add an index",
+	}, tc.Comment)
+
+	// Other events are ignored.
+	require.NoError(t, os.WriteFile(payload, []byte(`{"object_kind": "push"}`), 0644))
+	tc, err = act.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, tc.Comment)
+}
+
+func TestGitLabClient_Copilot(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(mockClientHandler(dir, "token"))
+	defer srv.Close()
+	c, err := atlasaction.NewGitLabClient("1", srv.URL, "token")
+	require.NoError(t, err)
+	tc := &atlasaction.TriggerContext{Comment: &atlasaction.Comment{Number: 2}}
+	s, err := c.CopilotSession(context.Background(), tc)
+	require.NoError(t, err)
+	require.Empty(t, s)
+	require.NoError(t, c.CommentCopilot(context.Background(), 2, &atlasaction.Copilot{
+		Session:  "s1",
+		Prompt:   "/atlas add an index",
+		Response: "Done.",
+	}))
+	b, err := os.ReadFile(filepath.Join(dir, "1"))
+	require.NoError(t, err)
+	require.Equal(t, "> /atlas add an index\n\nDone.\n\n<!-- generated by ariga/atlas-action/copilot for session s1 -->\n", string(b))
+	s, err = c.CopilotSession(context.Background(), tc)
+	require.NoError(t, err)
+	require.Equal(t, "s1", s)
+}
+
+func TestGitLabClient_CommentLintSuggestions(t *testing.T) {
+	var (
+		discussions []gitlab.Discussion
+		updated     []string
+	)
+	m := http.NewServeMux()
+	m.HandleFunc("GET /projects/1/merge_requests/2/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	m.HandleFunc("POST /projects/1/merge_requests/2/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	m.HandleFunc("GET /projects/1/merge_requests/2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"iid":2,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+	})
+	m.HandleFunc("GET /projects/1/merge_requests/2/diffs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"new_path":"db/migrations/1.sql"}]`))
+	})
+	m.HandleFunc("GET /projects/1/merge_requests/2/discussions", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(discussions))
+	})
+	m.HandleFunc("POST /projects/1/merge_requests/2/discussions", func(w http.ResponseWriter, r *http.Request) {
+		var n gitlab.Note
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		n.ID = len(discussions) + 1
+		discussions = append(discussions, gitlab.Discussion{ID: strconv.Itoa(n.ID), Notes: []gitlab.Note{n}})
+		w.WriteHeader(http.StatusCreated)
+	})
+	m.HandleFunc("PUT /projects/1/merge_requests/2/notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		updated = append(updated, r.PathValue("id"))
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	c, err := atlasaction.NewGitLabClient("1", srv.URL, "token")
+	require.NoError(t, err)
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{{
+			Name: "1.sql",
+			Text: "CREATE TABLE t(c int);\nALTER TABLE t ADD COLUMN d int NOT NULL;\n",
+			Reports: []sqlcheck.Report{{
+				Text: "data dependent changes detected",
+				Diagnostics: []sqlcheck.Diagnostic{{
+					Pos:  23,
+					Text: `Adding a non-nullable "int" column "d"`,
+					Code: "MY101",
+					SuggestedFixes: []sqlcheck.SuggestedFix{{
+						Message:  "Add a default value",
+						TextEdit: &sqlcheck.TextEdit{Line: 2, End: 3, NewText: "ALTER TABLE t\nADD COLUMN d int NOT NULL DEFAULT 0;"},
+					}},
+				}},
+			}},
+		}},
+	}
+	lint.Env.Dir = "migrations"
+	tc := &atlasaction.TriggerContext{
+		Act:         &mockAction{inputs: map[string]string{"working-directory": "db"}},
+		PullRequest: &atlasaction.PullRequest{Number: 2},
+	}
+	require.NoError(t, c.CommentLint(context.Background(), tc, lint))
+	require.Len(t, discussions, 1)
+	n := discussions[0].Notes[0]
+	require.Equal(t, &gitlab.Position{
+		BaseSHA:      "base",
+		StartSHA:     "start",
+		HeadSHA:      "head",
+		PositionType: "text",
+		NewPath:      "db/migrations/1.sql",
+		NewLine:      2,
+	}, n.Position)
+	require.Contains(t, n.Body, "```suggestion:-0+1\nALTER TABLE t\nADD COLUMN d int NOT NULL DEFAULT 0;\n```")
+	require.Contains(t, n.Body, "<!-- generated by ariga/atlas-action for Add a default value -->")
+
+	// Unchanged suggestions are not re-posted.
+	require.NoError(t, c.CommentLint(context.Background(), tc, lint))
+	require.Len(t, discussions, 1)
+	require.Empty(t, updated)
+
+	// Changed suggestions are updated in place.
+	lint.Files[0].Reports[0].Diagnostics[0].SuggestedFixes[0].TextEdit.NewText = "ALTER TABLE t\nADD COLUMN d int NOT NULL DEFAULT 1;"
+	require.NoError(t, c.CommentLint(context.Background(), tc, lint))
+	require.Len(t, discussions, 1)
+	require.Equal(t, []string{"1"}, updated)
+}
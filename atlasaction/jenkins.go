@@ -0,0 +1,150 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+	"github.com/magiconair/properties"
+)
+
+// Jenkins is an implementation of the Action interface for Jenkins.
+type Jenkins struct {
+	*coloredLogger
+	getenv func(string) string
+}
+
+var _ Action = (*Jenkins)(nil)
+
+// NewJenkins returns a new Jenkins.
+func NewJenkins(getenv func(string) string, w io.Writer) *Jenkins {
+	return &Jenkins{getenv: getenv, coloredLogger: &coloredLogger{w}}
+}
+
+// GetType implements the Action interface.
+func (a *Jenkins) GetType() atlasexec.TriggerType {
+	return atlasexec.TriggerType("JENKINS")
+}
+
+// Getenv implements Action.
+func (a *Jenkins) Getenv(key string) string {
+	return a.getenv(key)
+}
+
+// GetInput implements the Action interface.
+func (a *Jenkins) GetInput(name string) string {
+	// To pass inputs to the action, define environment variables with the ATLAS_INPUT_ prefix:
+	// ```groovy
+	// withEnv(["ATLAS_INPUT_DIR=file://migrations"]) {
+	//   sh 'atlas-action --action migrate/lint'
+	// }
+	// ```
+	return strings.TrimSpace(a.getenv(toInputVarName(name)))
+}
+
+// SetOutput implements the Action interface.
+func (a *Jenkins) SetOutput(name, value string) {
+	// Because Jenkins does not support output variables,
+	// we write the output to a properties file.
+	// So the next step can read the outputs using the readProperties step.
+	// e.g:
+	// ```groovy
+	// def outputs = readProperties file: '.atlas-action/outputs.properties'
+	// echo outputs['ATLAS_OUTPUT_MIGRATE_LINT_REPORT_URL']
+	// ```
+	path := filepath.Join(a.getenv("WORKSPACE"), ".atlas-action", "outputs.properties")
+	if out := a.getenv("ATLAS_OUTPUT_FILE"); out != "" {
+		// The user can set the output file using
+		// the ATLAS_OUTPUT_FILE environment variable.
+		path = out
+	}
+	err := writeProperty(path, toOutputVarName(a.getenv("ATLAS_ACTION_COMMAND"), name), value)
+	if err != nil {
+		a.Errorf("failed to write output to file %s: %v", path, err)
+	}
+}
+
+// GetTriggerContext implements the Action interface.
+// https://www.jenkins.io/doc/book/pipeline/jenkinsfile/#using-environment-variables
+func (a *Jenkins) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc := &TriggerContext{
+		Act:    a,
+		Branch: a.getenv("BRANCH_NAME"),
+		Commit: a.getenv("GIT_COMMIT"),
+	}
+	if tc.Commit == "" {
+		return nil, fmt.Errorf("missing GIT_COMMIT environment variable")
+	}
+	if u := a.getenv("GIT_URL"); u != "" {
+		var err error
+		if tc.RepoURL, tc.Repo, err = parseRemoteURL(u); err != nil {
+			return nil, err
+		}
+	}
+	if id := a.getenv("CHANGE_ID"); id != "" {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CHANGE_ID: %w", err)
+		}
+		tc.PullRequest = &PullRequest{
+			Number: n,
+			Commit: tc.Commit,
+		}
+		// On change requests, BRANCH_NAME is set to the
+		// name of the change (e.g. PR-1), not the source branch.
+		if b := a.getenv("CHANGE_BRANCH"); b != "" {
+			tc.Branch = b
+		}
+		// The target branch of the change is used as the base branch.
+		tc.DefaultBranch = a.getenv("CHANGE_TARGET")
+		if u := a.getenv("CHANGE_AUTHOR"); u != "" {
+			tc.Actor = &Actor{Name: u}
+		}
+	}
+	if err := tc.detectSCM(ctx); err != nil {
+		return nil, err
+	}
+	if pr := tc.PullRequest; pr != nil {
+		if u := a.getenv("CHANGE_URL"); u != "" {
+			pr.URL = u
+		}
+	}
+	return tc, nil
+}
+
+// writeProperty sets the given key and value in the properties file.
+func writeProperty(path, key, value string) error {
+	p := properties.NewProperties()
+	p.DisableExpansion = true
+	switch _, err := os.Stat(path); {
+	case err == nil:
+		l := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+		if p, err = l.LoadFile(path); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	if _, _, err := p.Set(key, value); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = p.Write(f, properties.UTF8)
+	return err
+}
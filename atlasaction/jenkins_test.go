@@ -0,0 +1,152 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/magiconair/properties"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJenkins_GetTriggerContext(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *atlasaction.TriggerContext
+	}{
+		{
+			name: "branch build",
+			env: map[string]string{
+				"GIT_COMMIT":  "abc123",
+				"BRANCH_NAME": "main",
+				"GIT_URL":     "https://github.com/ariga/atlas-action.git",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType: atlasexec.SCMTypeGithub,
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://github.com/ariga/atlas-action",
+				Branch:  "main",
+				Commit:  "abc123",
+			},
+		},
+		{
+			name: "change request",
+			env: map[string]string{
+				"GIT_COMMIT":    "abc123",
+				"BRANCH_NAME":   "PR-42",
+				"CHANGE_ID":     "42",
+				"CHANGE_URL":    "https://gitlab.com/ariga/atlas-action/-/merge_requests/42",
+				"CHANGE_TARGET": "master",
+				"CHANGE_BRANCH": "feature",
+				"CHANGE_AUTHOR": "a8m",
+				"GIT_URL":       "git@gitlab.com:ariga/atlas-action.git",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType:       atlasexec.SCMTypeGitlab,
+				Repo:          "ariga/atlas-action",
+				RepoURL:       "https://gitlab.com/ariga/atlas-action",
+				DefaultBranch: "master",
+				Branch:        "feature",
+				Commit:        "abc123",
+				Actor:         &atlasaction.Actor{Name: "a8m"},
+				PullRequest: &atlasaction.PullRequest{
+					Number: 42,
+					URL:    "https://gitlab.com/ariga/atlas-action/-/merge_requests/42",
+					Commit: "abc123",
+				},
+			},
+		},
+		{
+			name: "bitbucket change request without URL",
+			env: map[string]string{
+				"GIT_COMMIT": "abc123",
+				"CHANGE_ID":  "1",
+				"GIT_URL":    "https://bitbucket.org/ariga/atlas-action.git",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType: atlasexec.SCMTypeBitbucket,
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://bitbucket.org/ariga/atlas-action",
+				Commit:  "abc123",
+				PullRequest: &atlasaction.PullRequest{
+					Number: 1,
+					URL:    "https://bitbucket.org/ariga/atlas-action/pull-requests/1",
+					Commit: "abc123",
+				},
+			},
+		},
+		{
+			name: "unknown SCM",
+			env: map[string]string{
+				"GIT_COMMIT": "abc123",
+				"GIT_URL":    "https://git.example.com/ariga/atlas-action.git",
+			},
+			want: &atlasaction.TriggerContext{
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://git.example.com/ariga/atlas-action",
+				Commit:  "abc123",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := atlasaction.NewJenkins(func(k string) string { return tt.env[k] }, &bytes.Buffer{})
+			tc, err := a.GetTriggerContext(context.Background())
+			require.NoError(t, err)
+			if tt.want.SCMType != "" {
+				require.NotNil(t, tc.SCMClient)
+			}
+			tt.want.Act, tc.SCMClient = a, nil
+			require.Equal(t, tt.want, tc)
+		})
+	}
+	t.Run("unknown SCM change request", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		env := map[string]string{
+			"GIT_COMMIT": "abc123",
+			"CHANGE_ID":  "42",
+			"GIT_URL":    srv.URL + "/ariga/atlas-action.git",
+		}
+		a := atlasaction.NewJenkins(func(k string) string { return env[k] }, &bytes.Buffer{})
+		tc, err := a.GetTriggerContext(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, tc.SCMType)
+		require.Equal(t, 42, tc.PullRequest.Number)
+		_, err = tc.SCMClient()
+		require.EqualError(t, err, "unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+	})
+	t.Run("missing commit", func(t *testing.T) {
+		a := atlasaction.NewJenkins(func(string) string { return "" }, &bytes.Buffer{})
+		_, err := a.GetTriggerContext(context.Background())
+		require.EqualError(t, err, "missing GIT_COMMIT environment variable")
+	})
+}
+
+func TestJenkins_SetOutput(t *testing.T) {
+	dir := t.TempDir()
+	env := map[string]string{
+		"WORKSPACE":            dir,
+		"ATLAS_ACTION_COMMAND": "migrate/lint",
+	}
+	a := atlasaction.NewJenkins(func(k string) string { return env[k] }, &bytes.Buffer{})
+	a.SetOutput("report-url", "https://example.com/${report}")
+	a.SetOutput("report-url", "https://example.com/report")
+	a.SetOutput("comment", "line1\nline2")
+	p, err := properties.LoadFile(filepath.Join(dir, ".atlas-action", "outputs.properties"), properties.UTF8)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"ATLAS_OUTPUT_MIGRATE_LINT_REPORT_URL": "https://example.com/report",
+		"ATLAS_OUTPUT_MIGRATE_LINT_COMMENT":    "line1\nline2",
+	}, p.Map())
+}
@@ -0,0 +1,214 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/atlasexec"
+)
+
+type (
+	// junitTestSuites is the root element of a JUnit XML report.
+	// The format is supported by most CI platforms, e.g. GitLab and CircleCI.
+	junitTestSuites struct {
+		XMLName  xml.Name          `xml:"testsuites"`
+		Tests    int               `xml:"tests,attr"`
+		Failures int               `xml:"failures,attr"`
+		Skipped  int               `xml:"skipped,attr"`
+		Suites   []*junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Time     string           `xml:"time,attr,omitempty"`
+		Cases    []*junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr,omitempty"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		Skipped   *junitSkipped `xml:"skipped,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr,omitempty"`
+		Text    string `xml:",chardata"`
+	}
+	junitSkipped struct {
+		Message string `xml:"message,attr,omitempty"`
+	}
+)
+
+// add adds the test case to the suite and updates its counters.
+func (s *junitTestSuite) add(c *junitTestCase) {
+	s.Tests++
+	switch {
+	case c.Failure != nil:
+		s.Failures++
+	case c.Skipped != nil:
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, c)
+}
+
+// writeJUnit writes the suites as a JUnit report to the given path, replacing
+// any existing report. The directory of the file is created if it does not exist.
+func writeJUnit(path string, suites ...*junitTestSuite) error {
+	r := junitTestSuites{Suites: suites}
+	for _, s := range r.Suites {
+		r.Tests += s.Tests
+		r.Failures += s.Failures
+		r.Skipped += s.Skipped
+	}
+	buf, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(buf, '\n')...), 0644)
+}
+
+// appendJUnit appends the suite to the JUnit report in the given path.
+// If the file does not exist, it is created along with its directory.
+func appendJUnit(path string, suite *junitTestSuite) error {
+	var r junitTestSuites
+	switch buf, err := os.ReadFile(path); {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := xml.Unmarshal(buf, &r); err != nil {
+			return fmt.Errorf("failed to parse JUnit report %s: %w", path, err)
+		}
+	}
+	return writeJUnit(path, append(r.Suites, suite)...)
+}
+
+// lintJUnit returns a JUnit test suite for the given lint report.
+// Each file is a test case, and it is failed if it has errors.
+func lintJUnit(name, dir string, r *atlasexec.SummaryReport) *junitTestSuite {
+	suite := &junitTestSuite{Name: name}
+	for _, f := range r.Files {
+		c := &junitTestCase{
+			Name:      f.Name,
+			Classname: dir,
+		}
+		var diags []string
+		for _, rr := range f.Reports {
+			for _, d := range rr.Diagnostics {
+				diags = append(diags, fmt.Sprintf("%s:%d: %s: %s", filepath.Join(dir, f.Name),
+					diagnosticLine(f, d.Pos), rr.Text, diagnosticText(d.Text, d.Code)))
+			}
+		}
+		if f.Error != "" {
+			c.Failure = &junitFailure{
+				Message: f.Error,
+				Type:    "error",
+				Text:    strings.Join(diags, "\n"),
+			}
+		} else {
+			c.SystemOut = strings.Join(diags, "\n")
+		}
+		suite.add(c)
+	}
+	return suite
+}
+
+// schemaLintJUnit returns a JUnit test suite for the given schema lint report.
+// Each step is a test case, and it is failed if it reported an error.
+func schemaLintJUnit(name string, r *SchemaLintReport) *junitTestSuite {
+	suite := &junitTestSuite{Name: name}
+	for _, s := range r.Steps {
+		c := &junitTestCase{
+			Name:      s.Text,
+			Classname: name,
+		}
+		diags := make([]string, 0, len(s.Diagnostics))
+		for _, d := range s.Diagnostics {
+			text := diagnosticText(d.Text, d.Code)
+			if p := d.Pos; p != nil {
+				text = fmt.Sprintf("%s:%d: %s", p.Filename, max(1, p.Start.Line), text)
+			}
+			diags = append(diags, text)
+		}
+		if s.Error {
+			c.Failure = &junitFailure{
+				Message: s.Text,
+				Type:    "error",
+				Text:    strings.Join(diags, "\n"),
+			}
+		} else {
+			c.SystemOut = strings.Join(diags, "\n")
+		}
+		suite.add(c)
+	}
+	return suite
+}
+
+// testJUnit returns a JUnit test suite for the given test report. If the
+// output could not be parsed, the whole run is reported as a single test case.
+func testJUnit(r *TestReport) *junitTestSuite {
+	suite := &junitTestSuite{Name: r.Cmd}
+	if len(r.Tests) == 0 {
+		c := &junitTestCase{
+			Name:      r.Cmd,
+			Classname: r.Cmd,
+			SystemOut: r.Output,
+		}
+		if r.Error != "" {
+			c.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s failed", r.Cmd),
+				Type:    "error",
+				Text:    r.Error,
+			}
+		}
+		suite.add(c)
+		return suite
+	}
+	var total time.Duration
+	for _, t := range r.Tests {
+		c := &junitTestCase{
+			Name:      t.Name,
+			Classname: r.Cmd,
+			Time:      junitTime(t.Elapsed),
+		}
+		switch t.Status {
+		case TestFail:
+			c.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s failed", t.Name),
+				Type:    "error",
+				Text:    t.Output,
+			}
+		case TestSkip:
+			c.Skipped = &junitSkipped{Message: t.Output}
+		default:
+			c.SystemOut = t.Output
+		}
+		total += t.Elapsed
+		suite.add(c)
+	}
+	suite.Time = junitTime(total)
+	return suite
+}
+
+// junitTime formats the duration in seconds, as expected by JUnit.
+func junitTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,276 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/atlasexec"
+	"gopkg.in/yaml.v3"
+)
+
+// Local is an implementation of the Action interface for running
+// the actions outside any CI, e.g. on a developer machine.
+type Local struct {
+	*coloredLogger
+	getenv func(string) string
+	// Inputs holds the inputs given on the command line.
+	// They take precedence over the environment variables and the inputs file.
+	Inputs map[string]string
+	// InputsFile is the path to a JSON or YAML file holding the inputs.
+	InputsFile string
+	// OutputsFile is the path to the JSON file the outputs are written to.
+	OutputsFile string
+
+	wd       string // working directory at the time of creation.
+	once     sync.Once
+	fromFile map[string]string
+}
+
+var (
+	_ Action      = (*Local)(nil)
+	_ inputLister = (*Local)(nil)
+)
+
+// DefaultLocalOutputsFile is the default file to write the outputs to, when running locally.
+const DefaultLocalOutputsFile = ".atlas-action/outputs.json"
+
+// NewLocal returns a new Local.
+func NewLocal(getenv func(string) string, w io.Writer) *Local {
+	wd, _ := os.Getwd()
+	a := &Local{
+		getenv:        getenv,
+		coloredLogger: &coloredLogger{w},
+		InputsFile:    getenv("ATLAS_INPUTS_FILE"),
+		OutputsFile:   getenv("ATLAS_OUTPUTS_FILE"),
+		wd:            wd,
+	}
+	if a.OutputsFile == "" {
+		a.OutputsFile = DefaultLocalOutputsFile
+	}
+	return a
+}
+
+// GetType implements the Action interface.
+func (a *Local) GetType() atlasexec.TriggerType {
+	return atlasexec.TriggerTypeCLI
+}
+
+// Getenv implements Action.
+func (a *Local) Getenv(key string) string {
+	return a.getenv(key)
+}
+
+// GetInput implements the Action interface.
+func (a *Local) GetInput(name string) string {
+	if v, ok := a.Inputs[name]; ok {
+		return strings.TrimSpace(v)
+	}
+	if v := a.getenv(toInputVarName(name)); v != "" {
+		return strings.TrimSpace(v)
+	}
+	return strings.TrimSpace(a.inputsFile()[name])
+}
+
+// inputNames implements the inputLister interface. Only the inputs given on the command
+// line and in the inputs file are listed, as the environment cannot be enumerated.
+func (a *Local) inputNames() []string {
+	names := slices.Collect(maps.Keys(a.Inputs))
+	for k := range a.inputsFile() {
+		if !slices.Contains(names, k) {
+			names = append(names, k)
+		}
+	}
+	return names
+}
+
+// inputsFile returns the inputs read from the inputs file.
+func (a *Local) inputsFile() map[string]string {
+	a.once.Do(func() {
+		var err error
+		if a.fromFile, err = readInputsFile(a.path(a.InputsFile)); err != nil {
+			a.Fatalf("failed to read inputs from file %s: %v", a.InputsFile, err)
+		}
+	})
+	return a.fromFile
+}
+
+// SetOutput implements the Action interface.
+// The outputs are stored in a JSON file, grouped by the action name:
+//
+//	{"migrate/lint": {"report-url": "https://..."}}
+func (a *Local) SetOutput(name, value string) {
+	file := a.path(a.OutputsFile)
+	if err := writeOutput(file, a.getenv("ATLAS_ACTION_COMMAND"), name, value); err != nil {
+		a.Fatalf("failed to write output to file %s: %v", file, err)
+	}
+}
+
+// GetTriggerContext implements the Action interface.
+// The context is built from the git repository in the current working directory.
+func (a *Local) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	commit, err := a.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current commit: %w", err)
+	}
+	tc := &TriggerContext{Act: a, Commit: commit}
+	// An error is returned on a detached HEAD.
+	if b, err := a.git(ctx, "symbolic-ref", "--short", "HEAD"); err == nil {
+		tc.Branch = b
+	}
+	if b, err := a.git(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		tc.DefaultBranch = strings.TrimPrefix(b, "origin/")
+	}
+	if u, err := a.git(ctx, "remote", "get-url", "origin"); err == nil {
+		if tc.RepoURL, tc.Repo, err = parseRemoteURL(u); err != nil {
+			return nil, err
+		}
+	}
+	if err := tc.detectSCM(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// git runs the git command with the given arguments and returns its trimmed output.
+func (a *Local) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = a.wd
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// path resolves the given path relative to the
+// working directory at the time of creation.
+func (a *Local) path(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(a.wd, p)
+}
+
+// readInputsFile reads the inputs from the given JSON or YAML file.
+// Lists are joined by newlines and objects are encoded as JSON,
+// to match the format expected by GetArrayInput and GetVarsInput.
+func readInputsFile(name string) (map[string]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	// YAML is a superset of JSON.
+	var raw map[string]any
+	if err = yaml.Unmarshal(buf, &raw); err != nil {
+		return nil, err
+	}
+	return toInputs(raw)
+}
+
+// toInputs converts the given raw values to action inputs.
+func toInputs(raw map[string]any) (map[string]string, error) {
+	inputs := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			inputs[k] = v
+		case []any:
+			vs := make([]string, len(v))
+			for i := range v {
+				vs[i] = fmt.Sprint(v[i])
+			}
+			inputs[k] = strings.Join(vs, "\n")
+		case map[string]any:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("encoding input %q: %w", k, err)
+			}
+			inputs[k] = string(b)
+		default:
+			inputs[k] = fmt.Sprint(v)
+		}
+	}
+	return inputs, nil
+}
+
+// writeOutput sets the output of the given action in the JSON file.
+func writeOutput(file, act, name, value string) error {
+	outputs := make(map[string]map[string]string)
+	switch buf, err := os.ReadFile(file); {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	case len(buf) > 0:
+		if err = json.Unmarshal(buf, &outputs); err != nil {
+			return err
+		}
+	}
+	if outputs[act] == nil {
+		outputs[act] = make(map[string]string)
+	}
+	outputs[act][name] = value
+	buf, err := json.MarshalIndent(outputs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(buf, '\n'), 0644)
+}
+
+// parseRemoteURL converts the given git remote URL to
+// an HTTPS repository URL and returns it with the repository path.
+//
+//	git@github.com:ariga/atlas.git => https://github.com/ariga/atlas, ariga/atlas
+func parseRemoteURL(s string) (string, string, error) {
+	// SCP-like syntax: [user@]host:path
+	if !strings.Contains(s, "://") {
+		host, path, ok := strings.Cut(s, ":")
+		if !ok {
+			// Local repository, e.g. /path/to/repo.
+			return "", "", nil
+		}
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			host = h
+		}
+		s = "https://" + host + "/" + path
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing remote URL %q: %w", s, err)
+	}
+	switch u.Scheme {
+	case "file":
+		return "", "", nil
+	case "ssh", "git":
+		u.Scheme = "https"
+		u.Host = u.Hostname()
+	}
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, ".git")
+	return u.String(), strings.TrimPrefix(u.Path, "/"), nil
+}
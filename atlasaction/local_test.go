@@ -0,0 +1,112 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_New(t *testing.T) {
+	env := map[string]string{}
+	newActs := func() (*atlasaction.Actions, error) {
+		return atlasaction.New(
+			atlasaction.WithGetenv(func(k string) string { return env[k] }),
+			atlasaction.WithOut(&bytes.Buffer{}),
+			atlasaction.WithAtlas(&mockAtlas{}),
+		)
+	}
+	act, err := newActs()
+	require.NoError(t, err)
+	require.IsType(t, (*atlasaction.Local)(nil), act.Action)
+
+	// Unknown CI providers are not run locally.
+	env["CI"] = "true"
+	_, err = newActs()
+	require.EqualError(t, err, "atlasaction: no action found for the current environment")
+}
+
+func TestLocal_GetInput(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("inputs.yaml", []byte(`
+dir: file://migrations
+dev-url: sqlite://file?mode=memory
+schema:
+  - public
+  - private
+vars:
+  foo: bar
+`), 0600))
+	env := map[string]string{
+		"ATLAS_INPUTS_FILE":    "inputs.yaml",
+		"ATLAS_INPUT_DEV_URL":  "sqlite://dev?mode=memory",
+		"ATLAS_ACTION_COMMAND": "migrate/lint",
+		"ATLAS_INPUT_DIR_NAME": "name",
+	}
+	a := atlasaction.NewLocal(func(k string) string { return env[k] }, &bytes.Buffer{})
+	a.Inputs = map[string]string{"dir-name": "flag"}
+	require.Equal(t, "file://migrations", a.GetInput("dir"))
+	require.Equal(t, "sqlite://dev?mode=memory", a.GetInput("dev-url"))
+	require.Equal(t, "public\nprivate", a.GetInput("schema"))
+	require.Equal(t, `{"foo":"bar"}`, a.GetInput("vars"))
+	require.Equal(t, "flag", a.GetInput("dir-name"))
+	require.Empty(t, a.GetInput("repo-name"))
+
+	a.SetOutput("report-url", "https://example.com")
+	a.SetOutput("url", "https://example.com/url")
+	buf, err := os.ReadFile(filepath.Join(dir, atlasaction.DefaultLocalOutputsFile))
+	require.NoError(t, err)
+	var outputs map[string]map[string]string
+	require.NoError(t, json.Unmarshal(buf, &outputs))
+	require.Equal(t, map[string]map[string]string{
+		"migrate/lint": {
+			"report-url": "https://example.com",
+			"url":        "https://example.com/url",
+		},
+	}, outputs)
+}
+
+func TestLocal_GetTriggerContext(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=a8m", "GIT_AUTHOR_EMAIL=a8m@example.com",
+			"GIT_COMMITTER_NAME=a8m", "GIT_COMMITTER_EMAIL=a8m@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q", "-b", "feature")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	git("remote", "add", "origin", "git@github.com:ariga/atlas-action.git")
+	git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/master")
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+
+	a := atlasaction.NewLocal(func(string) string { return "" }, &bytes.Buffer{})
+	tc, err := a.GetTriggerContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, string(bytes.TrimSpace(out)), tc.Commit)
+	require.Equal(t, "feature", tc.Branch)
+	require.Equal(t, "master", tc.DefaultBranch)
+	require.Equal(t, "https://github.com/ariga/atlas-action", tc.RepoURL)
+	require.Equal(t, "ariga/atlas-action", tc.Repo)
+	require.Equal(t, atlasexec.SCMTypeGithub, tc.SCMType)
+	require.Nil(t, tc.PullRequest)
+}
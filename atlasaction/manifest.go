@@ -2,23 +2,17 @@
 // This source code is licensed under the Apache 2.0 license found
 // in the LICENSE file in the root directory of this source tree.
 
-//go:build manifest
-// +build manifest
-
 package atlasaction
 
 import (
 	"bytes"
-	"cmp"
 	_ "embed"
 	"fmt"
-	"io"
-	"iter"
 	"maps"
-	"os"
-	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -40,7 +34,7 @@ type (
 		Version     string                  `yaml:"-"` // Injected at runtime, not from YAML
 	}
 	ActionInput struct {
-		Type        string   `yaml:"type"`                  // e.g., "string", "boolean", "number", "enum", etc.
+		Type        string   `yaml:"type"`                  // e.g., "string", "boolean", "number", "duration", "enum", etc.
 		MultiLine   bool     `yaml:"multiLine,omitempty"`   // Indicates if the input accepts multiple lines (e.g., for lists)
 		Default     string   `yaml:"default,omitempty"`     // Default value for the input
 		Options     []string `yaml:"options,omitempty"`     // For enum inputs
@@ -65,311 +59,96 @@ func ParseManifest() (*ActionsManifest, error) {
 	return &actions, nil
 }
 
-func (a ActionsManifest) AsOptions() map[string]string {
-	opts := make(map[string]string, len(a.Actions))
-	for _, act := range a.Actions {
-		opts[strings.ReplaceAll(act.ID, "/", " ")] = act.Name
-	}
-	return opts
-}
-
-// AsSetupOptions returns the action options with "setup" prepended, for the Azure DevOps task.json.
-// "setup" is Azure-specific and not part of the manifest since it is CI infrastructure, not an Atlas operation.
-func (a ActionsManifest) AsSetupOptions() map[string]string {
-	opts := a.AsOptions()
-	opts["setup"] = "Setup Atlas"
-	return opts
-}
-
-func (a ActionSpec) SortedInputs() iter.Seq2[string, ActionInput] {
-	return SortedInputs(a.Inputs, []string{
-		"working-directory", "config", "env", "vars", "dev-url",
-	})
-}
-
-func (a ActionSpec) SortedOutputs() iter.Seq2[string, ActionOutput] {
-	return func(yield func(string, ActionOutput) bool) {
-		keys := slices.Sorted(maps.Keys(a.Outputs))
-		for _, k := range keys {
-			if !yield(k, a.Outputs[k]) {
-				return
-			}
-		}
-	}
-}
-
-// AzureInputs returns a sequence of AzureInputGroups, which groups action inputs by their keys.
-func (a ActionsManifest) AzureInputs() iter.Seq2[string, AzureInputGroups] {
-	inputs := make(map[string]AzureInputGroups)
-	for _, act := range a.Actions {
-		for k, v := range act.Inputs {
-			i, ok := inputs[k]
-			if !ok {
-				i.ActionInput = v
-			}
-			i.Groups = append(i.Groups, act.ID)
-			inputs[k] = i
-		}
-	}
-	return SortedInputs(inputs, []string{
-		"working-directory", "config", "env", "vars", "dev-url",
-	})
-}
-
-type AzureInputGroups struct {
-	ActionInput
-	Groups []string
-}
-
-func (a AzureInputGroups) VisibleRule() string {
-	slices.Sort(a.Groups)
-	rule := make([]string, 0, len(a.Groups))
-	for _, g := range a.Groups {
-		rule = append(rule, "action == "+strings.ReplaceAll(g, "/", " "))
-	}
-	return strings.Join(rule, " || ")
-}
-
-// AzureInputType returns the Azure DevOps input type for the action input.
-func (a ActionInput) AzureInputType() string {
-	switch a.Type {
-	case "boolean":
-		return "boolean"
-	case "number":
-		return "int"
-	case "string":
-		if a.MultiLine {
-			return "multiLine"
-		}
-		if len(a.Options) > 0 {
-			return "pickList"
-		}
-		fallthrough
-	default:
-		return "string"
-	}
-}
-
-// GitLabType returns the GitLab CI/CD input type for the action input. GitLab
-// only supports "array", "boolean", "number", and "string" as input types;
-// constrained values are expressed as a "string" with "options". The manifest's
-// "enum" type is therefore mapped to "string" (its options are emitted separately).
-func (a ActionInput) GitLabType() string {
-	if a.Type == "enum" {
-		return "string"
-	}
-	return a.Type
-}
+// manifestSpec returns the parsed manifest. It is parsed once and shared by all
+// callers, so unlike ParseManifest, the returned value must not be modified.
+var manifestSpec = sync.OnceValues(ParseManifest)
 
-// GitHubManifests writes the actions to the given path as GitHub Actions manifests.
-// It creates a directory for each action with the action ID as the name and writes
-// the action.yml file inside it. The action.yml file is generated using the
-// "action-yml.tmpl" template.
-func (a ActionsManifest) GitHubManifests(path string) error {
-	write := func(a ActionSpec) error {
-		dir := filepath.Join(path, a.ID)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("creating directory %s: %w", dir, err)
-		}
-		file, err := os.OpenFile(filepath.Join(dir, "action.yml"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
-			return fmt.Errorf("creating action.yml in %s: %w", dir, err)
-		}
-		defer file.Close()
-		return templates.ExecuteTemplate(file, "action-yml.tmpl", a)
-	}
-	for _, act := range a.Actions {
-		if act.ID == "" {
-			continue
-		}
-		if err := write(act); err != nil {
-			return fmt.Errorf("writing action %s: %w", act.ID, err)
+// Action returns the spec of the action with the given ID, or nil if it does not exist.
+func (a ActionsManifest) Action(id string) *ActionSpec {
+	for i := range a.Actions {
+		if a.Actions[i].ID == id {
+			return &a.Actions[i]
 		}
 	}
 	return nil
 }
 
-func (a ActionsManifest) GitLabTemplates(path string) error {
-	write := func(a ActionSpec) error {
-		if err := os.MkdirAll(path, 0755); err != nil {
-			return fmt.Errorf("creating directory %s: %w", path, err)
-		}
-		temp := filepath.Join(path, strings.ReplaceAll(a.ID, "/", "-"))
-		file, err := os.OpenFile(fmt.Sprintf("%s.yml", temp), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
-			return fmt.Errorf("creating %s: %w", temp, err)
-		}
-		defer file.Close()
-		return templates.ExecuteTemplate(file, "gitlab-yml.tmpl", a)
-	}
-	for _, act := range a.Actions {
-		if act.ID == "" {
-			continue
-		}
-		if err := write(act); err != nil {
-			return fmt.Errorf("writing action %s: %w", act.ID, err)
-		}
-	}
-	return nil
-}
+// hiddenInputs are inputs that are accepted by the actions,
+// but are intentionally not documented in the manifest.
+var hiddenInputs = []string{"baseline", "repo"}
 
-// orbParamName converts a manifest input name to a CircleCI orb parameter name (snake_case).
-func orbParamName(input string) string {
-	return strings.ReplaceAll(input, "-", "_")
+// inputLister is implemented by the actions that know the full
+// set of inputs they were given, e.g. the inputs passed on the
+// command line to the local runtime. It is used to detect unknown
+// inputs, which are otherwise silently ignored.
+type inputLister interface {
+	inputNames() []string
 }
 
-// orbParamType returns the CircleCI orb parameter type for the given action input.
-func orbParamType(v any) string {
-	input, ok := v.(ActionInput)
-	if !ok {
-		return "string"
-	}
-	switch input.Type {
-	case "boolean":
-		return "boolean"
-	case "number":
-		return "integer"
-	}
-	if len(input.Options) > 0 {
-		return "enum"
-	}
-	return "string"
-}
-
-// needGitHubEnv returns true for actions that accept github_repo_env and github_token_env.
-func needGitHubEnv(id string) bool {
-	switch id {
-	case "migrate/push", "migrate/lint", "schema/plan", "schema/plan/approve":
-		return true
-	}
-	return false
-}
-
-// OrbTemplates writes the actions and setup command to the given path as CircleCI orb command YAML files.
-func (a ActionsManifest) OrbTemplates(path string) error {
-	if err := os.MkdirAll(path, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", path, err)
-	}
-	version := "1"
-	for _, act := range a.Actions {
-		if act.Version != "" {
-			version = act.Version
-			break
-		}
-	}
-	setupFile, err := os.OpenFile(filepath.Join(path, "setup.yml"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+// ValidateInputs validates the inputs of the given action against its spec in the
+// manifest. All problems are reported together, in a single error. Actions that
+// are not defined in the manifest are not validated.
+func (a *Actions) ValidateInputs(act string) error {
+	m, err := manifestSpec()
 	if err != nil {
-		return fmt.Errorf("creating setup.yml: %w", err)
-	}
-	defer setupFile.Close()
-	if err := templates.ExecuteTemplate(setupFile, "orb-setup-yml.tmpl", map[string]string{"Version": version}); err != nil {
-		return fmt.Errorf("writing setup: %w", err)
-	}
-	write := func(spec ActionSpec) error {
-		name := strings.NewReplacer("/", "_", "-", "_").Replace(spec.ID)
-		file, err := os.OpenFile(filepath.Join(path, name+".yml"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
-			return fmt.Errorf("creating %s.yml: %w", name, err)
+		return fmt.Errorf("atlasaction: failed to parse the manifest: %w", err)
+	}
+	spec := m.Action(act)
+	if spec == nil {
+		return nil
+	}
+	var errs []string
+	if l, ok := a.Action.(inputLister); ok {
+		names := l.inputNames()
+		slices.Sort(names)
+		for _, name := range names {
+			if _, ok := spec.Inputs[name]; !ok && !slices.Contains(hiddenInputs, name) {
+				errs = append(errs, fmt.Sprintf("unknown input %q", name))
+			}
 		}
-		defer file.Close()
-		return templates.ExecuteTemplate(file, "orb-yml.tmpl", spec)
 	}
-	for _, act := range a.Actions {
-		if act.ID == "" {
+	for _, name := range slices.Sorted(maps.Keys(spec.Inputs)) {
+		in := spec.Inputs[name]
+		v := strings.TrimSpace(a.GetInput(name))
+		if v == "" {
+			if in.Required && in.Default == "" {
+				errs = append(errs, fmt.Sprintf("input %q is required", name))
+			}
 			continue
 		}
-		if err := write(act); err != nil {
-			return fmt.Errorf("writing action %s: %w", act.ID, err)
-		}
-	}
-	return nil
-}
-
-// TeamCityTemplates writes the actions to the given path as TeamCity build configuration templates.
-func (a ActionsManifest) TeamCityTemplates(path string) error {
-	write := func(a ActionSpec) error {
-		if err := os.MkdirAll(path, 0755); err != nil {
-			return fmt.Errorf("creating directory %s: %w", path, err)
-		}
-		name := "atlas-" + strings.ReplaceAll(a.ID, "/", "-")
-		file, err := os.OpenFile(filepath.Join(path, fmt.Sprintf("%s.yml", name)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
-			return fmt.Errorf("creating %s: %w", name, err)
+		if err := in.validate(v); err != nil {
+			errs = append(errs, fmt.Sprintf("input %q: %v", name, err))
 		}
-		defer file.Close()
-		return templates.ExecuteTemplate(file, "teamcity-yml.tmpl", a)
 	}
-	for _, act := range a.Actions {
-		if act.ID == "" {
-			continue
-		}
-		if err := write(act); err != nil {
-			return fmt.Errorf("writing action %s: %w", act.ID, err)
-		}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("invalid input for %q: %s", act, errs[0])
+	default:
+		return fmt.Errorf("invalid inputs for %q:\n  - %s", act, strings.Join(errs, "\n  - "))
 	}
-	return nil
-}
-
-// AzureTaskJSON writes the actions to the given path as an Azure DevOps task JSON file.
-// It creates a file with the given path and writes the action data using the
-// "azure-task-json.tmpl" template.
-func (a ActionsManifest) AzureTaskJSON(w io.Writer) error {
-	return templates.ExecuteTemplate(w, "task-json.tmpl", a)
 }
 
-// MarkdownDocs writes the actions to the given path as Markdown documentation files.
-func (a ActionsManifest) MarkdownDocs(path string) error {
-	write := func(doc string) error {
-		f, err := os.OpenFile(filepath.Join(path, doc), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
-			return fmt.Errorf("opening file %s: %w", path, err)
+// validate checks the given (non-empty) value against the input spec.
+func (a ActionInput) validate(v string) error {
+	switch a.Type {
+	case "boolean":
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("%q is not a valid boolean", v)
 		}
-		defer f.Close()
-		return templates.ExecuteTemplate(f, doc, a)
-	}
-	for _, doc := range []string{
-		"azure.mdx",
-		"bitbucket.mdx",
-	} {
-		if err := write(doc); err != nil {
-			return fmt.Errorf("writing doc %s: %w", doc, err)
+	case "number":
+		if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+			return fmt.Errorf("%q is not a valid non-negative number", v)
 		}
-	}
-	return nil
-}
-
-// MarkdownREADME writes the README documentation using the readme template.
-func (a ActionsManifest) MarkdownREADME(path string) error {
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return fmt.Errorf("opening file %s: %w", path, err)
-	}
-	defer file.Close()
-	return templates.ExecuteTemplate(file, "readme-md.tmpl", a)
-}
-
-func SortedInputs[Map ~map[K]V, K cmp.Ordered, V any](m Map, orders []K) iter.Seq2[K, V] {
-	return func(yield func(K, V) bool) {
-		keys := slices.SortedFunc(maps.Keys(m), ComparePriority(orders))
-		for _, k := range keys {
-			if !yield(k, m[k]) {
-				return
-			}
+	case "duration":
+		if _, err := parseDuration(v); err != nil {
+			return fmt.Errorf("%q is not a valid duration (e.g. 10, 10s, 1m)", v)
 		}
 	}
-}
-
-func ComparePriority[T cmp.Ordered](ordered []T) func(T, T) int {
-	return func(x, y T) int {
-		switch xi, yi := slices.Index(ordered, x), slices.Index(ordered, y); {
-		case xi != -1 && yi != -1:
-			return cmp.Compare(xi, yi)
-		case yi != -1:
-			return -1
-		case xi != -1:
-			return +1
-		}
-		// fallback to default comparison
-		return cmp.Compare(x, y)
+	// Options are matched case-insensitively, as commands accept values like "MIGRATION".
+	if len(a.Options) > 0 && !slices.ContainsFunc(a.Options, func(o string) bool { return strings.EqualFold(o, v) }) {
+		return fmt.Errorf("%q is not one of: %s", v, strings.Join(a.Options, ", "))
 	}
+	return nil
 }
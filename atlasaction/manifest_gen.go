@@ -0,0 +1,330 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+//go:build manifest
+// +build manifest
+
+package atlasaction
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func (a ActionsManifest) AsOptions() map[string]string {
+	opts := make(map[string]string, len(a.Actions))
+	for _, act := range a.Actions {
+		opts[strings.ReplaceAll(act.ID, "/", " ")] = act.Name
+	}
+	return opts
+}
+
+// AsSetupOptions returns the action options with "setup" prepended, for the Azure DevOps task.json.
+// "setup" is Azure-specific and not part of the manifest since it is CI infrastructure, not an Atlas operation.
+func (a ActionsManifest) AsSetupOptions() map[string]string {
+	opts := a.AsOptions()
+	opts["setup"] = "Setup Atlas"
+	return opts
+}
+
+func (a ActionSpec) SortedInputs() iter.Seq2[string, ActionInput] {
+	return SortedInputs(a.Inputs, []string{
+		"working-directory", "config", "env", "vars", "dev-url",
+	})
+}
+
+func (a ActionSpec) SortedOutputs() iter.Seq2[string, ActionOutput] {
+	return func(yield func(string, ActionOutput) bool) {
+		keys := slices.Sorted(maps.Keys(a.Outputs))
+		for _, k := range keys {
+			if !yield(k, a.Outputs[k]) {
+				return
+			}
+		}
+	}
+}
+
+// AzureInputs returns a sequence of AzureInputGroups, which groups action inputs by their keys.
+func (a ActionsManifest) AzureInputs() iter.Seq2[string, AzureInputGroups] {
+	inputs := make(map[string]AzureInputGroups)
+	for _, act := range a.Actions {
+		for k, v := range act.Inputs {
+			i, ok := inputs[k]
+			if !ok {
+				i.ActionInput = v
+			}
+			i.Groups = append(i.Groups, act.ID)
+			inputs[k] = i
+		}
+	}
+	return SortedInputs(inputs, []string{
+		"working-directory", "config", "env", "vars", "dev-url",
+	})
+}
+
+type AzureInputGroups struct {
+	ActionInput
+	Groups []string
+}
+
+func (a AzureInputGroups) VisibleRule() string {
+	slices.Sort(a.Groups)
+	rule := make([]string, 0, len(a.Groups))
+	for _, g := range a.Groups {
+		rule = append(rule, "action == "+strings.ReplaceAll(g, "/", " "))
+	}
+	return strings.Join(rule, " || ")
+}
+
+// AzureInputType returns the Azure DevOps input type for the action input.
+func (a ActionInput) AzureInputType() string {
+	switch a.Type {
+	case "boolean":
+		return "boolean"
+	case "number":
+		return "int"
+	case "string":
+		if a.MultiLine {
+			return "multiLine"
+		}
+		if len(a.Options) > 0 {
+			return "pickList"
+		}
+		fallthrough
+	default:
+		return "string"
+	}
+}
+
+// GitLabType returns the GitLab CI/CD input type for the action input. GitLab
+// only supports "array", "boolean", "number", and "string" as input types;
+// constrained values are expressed as a "string" with "options". The manifest's
+// "enum" and "duration" types are therefore mapped to "string" (the enum options
+// are emitted separately).
+func (a ActionInput) GitLabType() string {
+	if a.Type == "enum" || a.Type == "duration" {
+		return "string"
+	}
+	return a.Type
+}
+
+// GitHubManifests writes the actions to the given path as GitHub Actions manifests.
+// It creates a directory for each action with the action ID as the name and writes
+// the action.yml file inside it. The action.yml file is generated using the
+// "action-yml.tmpl" template.
+func (a ActionsManifest) GitHubManifests(path string) error {
+	write := func(a ActionSpec) error {
+		dir := filepath.Join(path, a.ID)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+		file, err := os.OpenFile(filepath.Join(dir, "action.yml"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("creating action.yml in %s: %w", dir, err)
+		}
+		defer file.Close()
+		return templates.ExecuteTemplate(file, "action-yml.tmpl", a)
+	}
+	for _, act := range a.Actions {
+		if act.ID == "" {
+			continue
+		}
+		if err := write(act); err != nil {
+			return fmt.Errorf("writing action %s: %w", act.ID, err)
+		}
+	}
+	return nil
+}
+
+func (a ActionsManifest) GitLabTemplates(path string) error {
+	write := func(a ActionSpec) error {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", path, err)
+		}
+		temp := filepath.Join(path, strings.ReplaceAll(a.ID, "/", "-"))
+		file, err := os.OpenFile(fmt.Sprintf("%s.yml", temp), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", temp, err)
+		}
+		defer file.Close()
+		return templates.ExecuteTemplate(file, "gitlab-yml.tmpl", a)
+	}
+	for _, act := range a.Actions {
+		if act.ID == "" {
+			continue
+		}
+		if err := write(act); err != nil {
+			return fmt.Errorf("writing action %s: %w", act.ID, err)
+		}
+	}
+	return nil
+}
+
+// orbParamName converts a manifest input name to a CircleCI orb parameter name (snake_case).
+func orbParamName(input string) string {
+	return strings.ReplaceAll(input, "-", "_")
+}
+
+// orbParamType returns the CircleCI orb parameter type for the given action input.
+func orbParamType(v any) string {
+	input, ok := v.(ActionInput)
+	if !ok {
+		return "string"
+	}
+	switch input.Type {
+	case "boolean":
+		return "boolean"
+	case "number":
+		return "integer"
+	}
+	if len(input.Options) > 0 {
+		return "enum"
+	}
+	return "string"
+}
+
+// needGitHubEnv returns true for actions that accept github_repo_env and github_token_env.
+func needGitHubEnv(id string) bool {
+	switch id {
+	case "migrate/push", "migrate/lint", "schema/plan", "schema/plan/approve":
+		return true
+	}
+	return false
+}
+
+// OrbTemplates writes the actions and setup command to the given path as CircleCI orb command YAML files.
+func (a ActionsManifest) OrbTemplates(path string) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", path, err)
+	}
+	version := "1"
+	for _, act := range a.Actions {
+		if act.Version != "" {
+			version = act.Version
+			break
+		}
+	}
+	setupFile, err := os.OpenFile(filepath.Join(path, "setup.yml"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("creating setup.yml: %w", err)
+	}
+	defer setupFile.Close()
+	if err := templates.ExecuteTemplate(setupFile, "orb-setup-yml.tmpl", map[string]string{"Version": version}); err != nil {
+		return fmt.Errorf("writing setup: %w", err)
+	}
+	write := func(spec ActionSpec) error {
+		name := strings.NewReplacer("/", "_", "-", "_").Replace(spec.ID)
+		file, err := os.OpenFile(filepath.Join(path, name+".yml"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("creating %s.yml: %w", name, err)
+		}
+		defer file.Close()
+		return templates.ExecuteTemplate(file, "orb-yml.tmpl", spec)
+	}
+	for _, act := range a.Actions {
+		if act.ID == "" {
+			continue
+		}
+		if err := write(act); err != nil {
+			return fmt.Errorf("writing action %s: %w", act.ID, err)
+		}
+	}
+	return nil
+}
+
+// TeamCityTemplates writes the actions to the given path as TeamCity build configuration templates.
+func (a ActionsManifest) TeamCityTemplates(path string) error {
+	write := func(a ActionSpec) error {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", path, err)
+		}
+		name := "atlas-" + strings.ReplaceAll(a.ID, "/", "-")
+		file, err := os.OpenFile(filepath.Join(path, fmt.Sprintf("%s.yml", name)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", name, err)
+		}
+		defer file.Close()
+		return templates.ExecuteTemplate(file, "teamcity-yml.tmpl", a)
+	}
+	for _, act := range a.Actions {
+		if act.ID == "" {
+			continue
+		}
+		if err := write(act); err != nil {
+			return fmt.Errorf("writing action %s: %w", act.ID, err)
+		}
+	}
+	return nil
+}
+
+// AzureTaskJSON writes the actions to the given path as an Azure DevOps task JSON file.
+// It creates a file with the given path and writes the action data using the
+// "azure-task-json.tmpl" template.
+func (a ActionsManifest) AzureTaskJSON(w io.Writer) error {
+	return templates.ExecuteTemplate(w, "task-json.tmpl", a)
+}
+
+// MarkdownDocs writes the actions to the given path as Markdown documentation files.
+func (a ActionsManifest) MarkdownDocs(path string) error {
+	write := func(doc string) error {
+		f, err := os.OpenFile(filepath.Join(path, doc), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("opening file %s: %w", path, err)
+		}
+		defer f.Close()
+		return templates.ExecuteTemplate(f, doc, a)
+	}
+	for _, doc := range []string{
+		"azure.mdx",
+		"bitbucket.mdx",
+	} {
+		if err := write(doc); err != nil {
+			return fmt.Errorf("writing doc %s: %w", doc, err)
+		}
+	}
+	return nil
+}
+
+// MarkdownREADME writes the README documentation using the readme template.
+func (a ActionsManifest) MarkdownREADME(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("opening file %s: %w", path, err)
+	}
+	defer file.Close()
+	return templates.ExecuteTemplate(file, "readme-md.tmpl", a)
+}
+
+func SortedInputs[Map ~map[K]V, K cmp.Ordered, V any](m Map, orders []K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		keys := slices.SortedFunc(maps.Keys(m), ComparePriority(orders))
+		for _, k := range keys {
+			if !yield(k, m[k]) {
+				return
+			}
+		}
+	}
+}
+
+func ComparePriority[T cmp.Ordered](ordered []T) func(T, T) int {
+	return func(x, y T) int {
+		switch xi, yi := slices.Index(ordered, x), slices.Index(ordered, y); {
+		case xi != -1 && yi != -1:
+			return cmp.Compare(xi, yi)
+		case yi != -1:
+			return -1
+		case xi != -1:
+			return +1
+		}
+		// fallback to default comparison
+		return cmp.Compare(x, y)
+	}
+}
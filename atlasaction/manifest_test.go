@@ -0,0 +1,102 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	m, err := atlasaction.ParseManifest()
+	require.NoError(t, err)
+	spec := m.Action(atlasaction.CmdMigrateApply)
+	require.NotNil(t, spec)
+	require.Equal(t, "boolean", spec.Inputs["dry-run"].Type)
+	// Common inputs are merged into each action.
+	require.Contains(t, spec.Inputs, "working-directory")
+	require.Nil(t, m.Action("unknown"))
+}
+
+func TestActions_ValidateInputs(t *testing.T) {
+	newActs := func(t *testing.T, inputs map[string]string) *atlasaction.Actions {
+		act := atlasaction.NewLocal(func(string) string { return "" }, &bytes.Buffer{})
+		act.Inputs = inputs
+		acts, err := atlasaction.New(atlasaction.WithAction(act))
+		require.NoError(t, err)
+		return acts
+	}
+	t.Run("valid", func(t *testing.T) {
+		acts := newActs(t, map[string]string{
+			"dir":        "file://migrations",
+			"url":        "sqlite://file?mode=memory",
+			"amount":     "2",
+			"dry-run":    "true",
+			"exec-order": "non-linear",
+			"baseline":   "20240101000000",
+		})
+		require.NoError(t, acts.ValidateInputs(atlasaction.CmdMigrateApply))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		acts := newActs(t, map[string]string{
+			"dir-name":    "app",
+			"lint-reveiw": "ERROR",
+			"exec-order":  "foo",
+			"amount":      "-1",
+			"dry-run":     "yes",
+		})
+		require.EqualError(t, acts.ValidateInputs(atlasaction.CmdMigrateApply), `invalid inputs for "migrate/apply":
+  - unknown input "dir-name"
+  - unknown input "lint-reveiw"
+  - input "amount": "-1" is not a valid non-negative number
+  - input "dry-run": "yes" is not a valid boolean
+  - input "exec-order": "foo" is not one of: linear, linear-skip, non-linear`)
+	})
+	t.Run("required", func(t *testing.T) {
+		acts := newActs(t, map[string]string{
+			"dir": "file://migrations",
+		})
+		err := acts.Run(context.Background(), atlasaction.CmdMigrateLint)
+		require.EqualError(t, err, `invalid input for "migrate/lint": input "dir-name" is required`)
+	})
+	t.Run("options", func(t *testing.T) {
+		acts := newActs(t, map[string]string{
+			"driver": "MYSQL",
+			"type":   "MIGRATION",
+			"name":   "app",
+		})
+		require.NoError(t, acts.ValidateInputs(atlasaction.CmdCloudRepoCreate))
+	})
+	t.Run("duration", func(t *testing.T) {
+		acts := newActs(t, map[string]string{
+			"wait-timeout": "10",
+		})
+		require.NoError(t, acts.ValidateInputs(atlasaction.CmdSchemaApply))
+		acts = newActs(t, map[string]string{
+			"wait-interval": "1s",
+			"wait-timeout":  "1m",
+		})
+		require.NoError(t, acts.ValidateInputs(atlasaction.CmdMigrateDown))
+		acts = newActs(t, map[string]string{
+			"wait-timeout": "ten",
+		})
+		err := acts.ValidateInputs(atlasaction.CmdSchemaApply)
+		require.EqualError(t, err, `invalid input for "schema/apply": input "wait-timeout": "ten" is not a valid duration (e.g. 10, 10s, 1m)`)
+	})
+	t.Run("pipeline", func(t *testing.T) {
+		acts := newActs(t, map[string]string{"file": "pipeline.yml"})
+		require.NoError(t, acts.ValidateInputs(atlasaction.CmdPipeline))
+		acts = newActs(t, map[string]string{})
+		require.EqualError(t, acts.ValidateInputs(atlasaction.CmdPipeline), `invalid input for "pipeline": input "file" is required`)
+	})
+	t.Run("not in manifest", func(t *testing.T) {
+		acts := newActs(t, map[string]string{"file": "pipeline.yml"})
+		require.NoError(t, acts.ValidateInputs("unknown"))
+	})
+}
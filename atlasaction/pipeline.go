@@ -0,0 +1,286 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Pipeline is a list of actions that run in order against the same trigger context.
+	//
+	//	steps:
+	//	  - id: lint
+	//	    action: migrate/lint
+	//	    with:
+	//	      dir: file://migrations
+	//	      dir-name: app
+	//	      dev-url: sqlite://dev?mode=memory
+	//	  - id: apply
+	//	    action: migrate/apply
+	//	    with:
+	//	      dir: file://migrations
+	//	      url: ${{ env.DATABASE_URL }}
+	//	      vars:
+	//	        report: ${{ steps.lint.outputs.report-url }}
+	Pipeline struct {
+		Steps []*PipelineStep `yaml:"steps"`
+	}
+	// PipelineStep is a single action in the pipeline.
+	PipelineStep struct {
+		ID     string         `yaml:"id"`
+		Action string         `yaml:"action"`
+		With   map[string]any `yaml:"with"`
+	}
+	// stepAction is the Action of a single pipeline step. Its inputs are
+	// taken from the step definition, and its outputs are recorded for the
+	// next steps and forwarded to the underlying action.
+	stepAction struct {
+		Action
+		id      string
+		inputs  map[string]string
+		outputs map[string]string
+		tc      func(context.Context) (*TriggerContext, error)
+	}
+)
+
+var (
+	_ Action       = (*stepAction)(nil)
+	_ Reporter     = (*stepAction)(nil)
+	_ TestReporter = (*stepAction)(nil)
+	_ inputLister  = (*stepAction)(nil)
+)
+
+// ReadPipeline reads the pipeline definition from the given YAML file.
+func ReadPipeline(name string) (*Pipeline, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline file: %w", err)
+	}
+	var p Pipeline
+	// Reject unknown keys, e.g. a misspelled "with" of a step.
+	dec := yaml.NewDecoder(bytes.NewReader(buf))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse pipeline file: %w", err)
+	}
+	if len(p.Steps) == 0 {
+		return nil, errors.New("pipeline has no steps")
+	}
+	ids := make(map[string]bool, len(p.Steps))
+	for i, s := range p.Steps {
+		switch {
+		case s.Action == "":
+			return nil, fmt.Errorf("step %d: missing action", i+1)
+		case s.Action == CmdPipeline:
+			return nil, fmt.Errorf("step %d: nested pipelines are not supported", i+1)
+		case s.ID == "":
+			// Steps without an ID cannot be referenced,
+			// but their outputs are still forwarded.
+			s.ID = fmt.Sprintf("step-%d", i+1)
+		}
+		if ids[s.ID] {
+			return nil, fmt.Errorf("step %d: duplicate step id %q", i+1, s.ID)
+		}
+		ids[s.ID] = true
+	}
+	return &p, nil
+}
+
+// Pipeline runs the "pipeline" command. It runs the steps defined in the
+// pipeline file in order, and stops on the first failure.
+func (a *Actions) Pipeline(ctx context.Context) error {
+	file := a.GetInput("file")
+	if file == "" {
+		return errors.New("atlasaction: the pipeline file is required")
+	}
+	p, err := ReadPipeline(file)
+	if err != nil {
+		return err
+	}
+	// Steps may change the working directory,
+	// so it is restored before running the next one.
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var (
+		tc      *TriggerContext
+		outputs = make(map[string]map[string]string, len(p.Steps))
+	)
+	getTC := func(ctx context.Context) (*TriggerContext, error) {
+		if tc == nil {
+			// The trigger context is resolved once for all steps.
+			if tc, err = a.GetTriggerContext(ctx); err != nil {
+				tc = nil
+				return nil, err
+			}
+		}
+		return tc, nil
+	}
+	for _, s := range p.Steps {
+		with, err := s.inputs(a, outputs)
+		if err != nil {
+			return fmt.Errorf("step %q (%s): %w", s.ID, s.Action, err)
+		}
+		act := &stepAction{
+			Action:  a.Action,
+			id:      s.ID,
+			inputs:  with,
+			outputs: make(map[string]string),
+			tc:      getTC,
+		}
+		outputs[s.ID] = act.outputs
+		a.Infof("Running step %q (%s)", s.ID, s.Action)
+		err = (&Actions{
+			Action:      act,
+			Version:     a.Version,
+			Atlas:       a.Atlas,
+			CmdExecutor: a.CmdExecutor,
+			CloudClient: a.CloudClient,
+		}).Run(ctx, s.Action)
+		if err := errors.Join(err, os.Chdir(wd)); err != nil {
+			return fmt.Errorf("step %q (%s) failed: %w", s.ID, s.Action, err)
+		}
+	}
+	return nil
+}
+
+// exprRe matches the expressions that can be used in the step inputs.
+// e.g. ${{ steps.lint.outputs.report-url }} or ${{ env.DATABASE_URL }}.
+var exprRe = regexp.MustCompile(`\$\{\{\s*([^}]*?)\s*\}\}`)
+
+// inputs returns the inputs of the step after evaluating
+// the expressions with the outputs of the previous steps.
+func (s *PipelineStep) inputs(a Action, outputs map[string]map[string]string) (map[string]string, error) {
+	var (
+		errs   []error
+		expand func(any) any
+	)
+	expand = func(v any) any {
+		switch v := v.(type) {
+		case string:
+			return exprRe.ReplaceAllStringFunc(v, func(m string) string {
+				expr := exprRe.FindStringSubmatch(m)[1]
+				switch parts := strings.Split(expr, "."); {
+				case len(parts) == 2 && parts[0] == "env":
+					return a.Getenv(parts[1])
+				case len(parts) == 4 && parts[0] == "steps" && parts[2] == "outputs":
+					out, ok := outputs[parts[1]]
+					if !ok {
+						errs = append(errs, fmt.Errorf("step %q did not run before this step", parts[1]))
+						return m
+					}
+					return out[parts[3]]
+				default:
+					errs = append(errs, fmt.Errorf("unsupported expression %q", expr))
+					return m
+				}
+			})
+		case []any:
+			vs := make([]any, len(v))
+			for i := range v {
+				vs[i] = expand(v[i])
+			}
+			return vs
+		case map[string]any:
+			vs := make(map[string]any, len(v))
+			for k := range v {
+				vs[k] = expand(v[k])
+			}
+			return vs
+		default:
+			return v
+		}
+	}
+	with := expand(s.With).(map[string]any)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return toInputs(with)
+}
+
+// GetInput implements the Action interface.
+func (a *stepAction) GetInput(name string) string {
+	return strings.TrimSpace(a.inputs[name])
+}
+
+// inputNames implements the inputLister interface.
+func (a *stepAction) inputNames() []string {
+	return slices.Collect(maps.Keys(a.inputs))
+}
+
+// SetOutput implements the Action interface.
+// The output is prefixed with the step ID when forwarded to the underlying action.
+func (a *stepAction) SetOutput(name, value string) {
+	a.outputs[name] = value
+	a.Action.SetOutput(a.id+"-"+name, value)
+}
+
+// GetTriggerContext implements the Action interface.
+func (a *stepAction) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc, err := a.tc(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Shallow copy, to let the step read its own inputs.
+	c := *tc
+	c.Act = a
+	return &c, nil
+}
+
+// MigrateApply implements the Reporter interface.
+func (a *stepAction) MigrateApply(ctx context.Context, r *atlasexec.MigrateApply) {
+	if r1, ok := a.Action.(Reporter); ok {
+		r1.MigrateApply(ctx, r)
+	}
+}
+
+// MigrateLint implements the Reporter interface.
+func (a *stepAction) MigrateLint(ctx context.Context, r *atlasexec.SummaryReport) {
+	if r1, ok := a.Action.(Reporter); ok {
+		r1.MigrateLint(ctx, r)
+	}
+}
+
+// SchemaPlan implements the Reporter interface.
+func (a *stepAction) SchemaPlan(ctx context.Context, r *atlasexec.SchemaPlan) {
+	if r1, ok := a.Action.(Reporter); ok {
+		r1.SchemaPlan(ctx, r)
+	}
+}
+
+// SchemaApply implements the Reporter interface.
+func (a *stepAction) SchemaApply(ctx context.Context, r *atlasexec.SchemaApply) {
+	if r1, ok := a.Action.(Reporter); ok {
+		r1.SchemaApply(ctx, r)
+	}
+}
+
+// SchemaLint implements the Reporter interface.
+func (a *stepAction) SchemaLint(ctx context.Context, r *SchemaLintReport) {
+	if r1, ok := a.Action.(Reporter); ok {
+		r1.SchemaLint(ctx, r)
+	}
+}
+
+// TestResults implements the TestReporter interface.
+func (a *stepAction) TestResults(ctx context.Context, r *TestReport) {
+	if r1, ok := a.Action.(TestReporter); ok {
+		r1.TestResults(ctx, r)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, "pipeline.yml")
+	)
+	t.Setenv("SCHEMA_URL", "file://schema.hcl")
+	var pushed []*atlasexec.SchemaPushParams
+	cli := &mockAtlas{
+		schemaPush: func(_ context.Context, p *atlasexec.SchemaPushParams) (*atlasexec.SchemaPush, error) {
+			if p.Name == "fail" {
+				return nil, errors.New("push failed")
+			}
+			pushed = append(pushed, p)
+			return &atlasexec.SchemaPush{
+				Link: "https://a8m.atlasgo.cloud/schemas/" + p.Name,
+				Slug: p.Name,
+			}, nil
+		},
+	}
+	newActs := func(t *testing.T, act *mockAction) *atlasaction.Actions {
+		acts, err := atlasaction.New(atlasaction.WithAction(act), atlasaction.WithAtlas(cli))
+		require.NoError(t, err)
+		return acts
+	}
+	t.Run("outputs", func(t *testing.T) {
+		pushed = nil
+		require.NoError(t, os.WriteFile(file, []byte(`
+steps:
+  - id: app
+    action: schema/push
+    with:
+      schema-name: app
+      url: ${{ env.SCHEMA_URL }}
+  - action: schema/push
+    with:
+      schema-name: ${{ steps.app.outputs.slug }}-copy
+      url:
+        - ${{ env.SCHEMA_URL }}
+        - file://extra.hcl
+      vars:
+        link: ${{ steps.app.outputs.link }}
+`), 0600))
+		act := &mockAction{
+			inputs:  map[string]string{"file": file},
+			trigger: &atlasaction.TriggerContext{Commit: "abc123"},
+		}
+		require.NoError(t, newActs(t, act).Run(context.Background(), atlasaction.CmdPipeline))
+		require.Len(t, pushed, 2)
+		require.Equal(t, "app", pushed[0].Name)
+		require.Equal(t, []string{"file://schema.hcl"}, pushed[0].URL)
+		require.Equal(t, "abc123", pushed[0].Tag)
+		require.Equal(t, "app-copy", pushed[1].Name)
+		require.Equal(t, []string{"file://schema.hcl", "file://extra.hcl"}, pushed[1].URL)
+		require.Equal(t, atlasexec.Vars2{"link": "https://a8m.atlasgo.cloud/schemas/app"}, pushed[1].Vars)
+		require.Equal(t, map[string]string{
+			"app-link":    "https://a8m.atlasgo.cloud/schemas/app",
+			"app-slug":    "app",
+			"app-url":     "",
+			"step-2-link": "https://a8m.atlasgo.cloud/schemas/app-copy",
+			"step-2-slug": "app-copy",
+			"step-2-url":  "",
+		}, act.output)
+	})
+	t.Run("stop on failure", func(t *testing.T) {
+		pushed = nil
+		require.NoError(t, os.WriteFile(file, []byte(`
+steps:
+  - id: first
+    action: schema/push
+    with:
+      schema-name: fail
+  - id: second
+    action: schema/push
+    with:
+      schema-name: app
+`), 0600))
+		act := &mockAction{
+			inputs:  map[string]string{"file": file},
+			trigger: &atlasaction.TriggerContext{Commit: "abc123"},
+		}
+		err := newActs(t, act).Run(context.Background(), atlasaction.CmdPipeline)
+		require.EqualError(t, err, `step "first" (schema/push) failed: failed to push schema tag: push failed`)
+		require.Empty(t, pushed)
+	})
+	t.Run("invalid reference", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`
+steps:
+  - id: first
+    action: schema/push
+    with:
+      schema-name: ${{ steps.second.outputs.slug }}
+  - id: second
+    action: schema/push
+`), 0600))
+		act := &mockAction{
+			inputs:  map[string]string{"file": file},
+			trigger: &atlasaction.TriggerContext{Commit: "abc123"},
+		}
+		err := newActs(t, act).Run(context.Background(), atlasaction.CmdPipeline)
+		require.EqualError(t, err, `step "first" (schema/push): step "second" did not run before this step`)
+	})
+	t.Run("invalid file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`
+steps:
+  - id: first
+    action: pipeline
+`), 0600))
+		act := &mockAction{inputs: map[string]string{"file": file}}
+		err := newActs(t, act).Run(context.Background(), atlasaction.CmdPipeline)
+		require.EqualError(t, err, "step 1: nested pipelines are not supported")
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte(`
+steps:
+  - id: first
+    action: schema/push
+    inputs:
+      schema-name: app
+`), 0600))
+		act := &mockAction{inputs: map[string]string{"file": file}}
+		err := newActs(t, act).Run(context.Background(), atlasaction.CmdPipeline)
+		require.EqualError(t, err, "failed to parse pipeline file: yaml: unmarshal errors:\n  line 5: field inputs not found in type atlasaction.PipelineStep")
+	})
+	t.Run("empty file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+		act := &mockAction{inputs: map[string]string{"file": file}}
+		err := newActs(t, act).Run(context.Background(), atlasaction.CmdPipeline)
+		require.EqualError(t, err, "pipeline has no steps")
+	})
+}
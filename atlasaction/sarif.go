@@ -0,0 +1,259 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifRuleID is the rule used for findings without an analyzer code.
+	sarifRuleID = "atlas"
+)
+
+type (
+	// sarifLog is the root object of a SARIF 2.1.0 file.
+	// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+	sarifLog struct {
+		Schema  string      `json:"$schema"`
+		Version string      `json:"version"`
+		Runs    []*sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool      `json:"tool"`
+		Results []*sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string       `json:"name"`
+		Version        string       `json:"version,omitempty"`
+		InformationURI string       `json:"informationUri"`
+		Rules          []*sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string        `json:"id"`
+		ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+		FullDescription  *sarifMessage `json:"fullDescription,omitempty"`
+		HelpURI          string        `json:"helpUri"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string           `json:"ruleId"`
+		RuleIndex int              `json:"ruleIndex"`
+		Level     string           `json:"level"`
+		Message   sarifMessage     `json:"message"`
+		Locations []*sarifLocation `json:"locations,omitempty"`
+		Fixes     []*sarifFix      `json:"fixes,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+		EndLine   int `json:"endLine,omitempty"`
+	}
+	sarifFix struct {
+		Description     *sarifMessage          `json:"description,omitempty"`
+		ArtifactChanges []*sarifArtifactChange `json:"artifactChanges"`
+	}
+	sarifArtifactChange struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Replacements     []*sarifReplacement   `json:"replacements"`
+	}
+	sarifReplacement struct {
+		DeletedRegion   sarifRegion   `json:"deletedRegion"`
+		InsertedContent *sarifMessage `json:"insertedContent,omitempty"`
+	}
+)
+
+// newSARIFRun returns a new run for the given version of the action.
+func newSARIFRun(version string) *sarifRun {
+	return &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "Atlas",
+				Version:        version,
+				InformationURI: "https://atlasgo.io",
+				Rules:          []*sarifRule{},
+			},
+		},
+		Results: []*sarifResult{},
+	}
+}
+
+// rule returns the index of the rule for the given analyzer code, and adds it to the run if it
+// does not exist. The description is taken from the first report that uses the rule.
+func (r *sarifRun) rule(code, text, desc string) int {
+	if code == "" {
+		code, text, desc = sarifRuleID, "Atlas finding", ""
+	}
+	for i, rule := range r.Tool.Driver.Rules {
+		if rule.ID == code {
+			return i
+		}
+	}
+	rule := &sarifRule{
+		ID:      code,
+		HelpURI: "https://atlasgo.io/lint/analyzers",
+	}
+	if code != sarifRuleID {
+		rule.HelpURI += "#" + code
+	}
+	if text != "" {
+		rule.ShortDescription = &sarifMessage{Text: text}
+	}
+	if desc != "" {
+		rule.FullDescription = &sarifMessage{Text: desc}
+	}
+	r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, rule)
+	return len(r.Tool.Driver.Rules) - 1
+}
+
+// add adds a result for the given finding to the run.
+func (r *sarifRun) add(code, title, desc, text string, isErr bool, loc *sarifLocation, fixes []*sarifFix) {
+	res := &sarifResult{
+		Level:   "warning",
+		Message: sarifMessage{Text: text},
+		Fixes:   fixes,
+	}
+	if isErr {
+		res.Level = "error"
+	}
+	res.RuleIndex = r.rule(code, title, desc)
+	res.RuleID = r.Tool.Driver.Rules[res.RuleIndex].ID
+	if title != "" {
+		res.Message.Text = fmt.Sprintf("%s: %s", title, text)
+	}
+	if loc != nil {
+		res.Locations = []*sarifLocation{loc}
+	}
+	r.Results = append(r.Results, res)
+}
+
+// sarifFileLocation returns the location of the given line in the file.
+func sarifFileLocation(path string, line int) *sarifLocation {
+	return &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(path)},
+			Region:           &sarifRegion{StartLine: max(1, line)},
+		},
+	}
+}
+
+// sarifFixes converts the suggested fixes with text edits to SARIF fixes.
+func sarifFixes(path string, fixes []sqlcheck.SuggestedFix) (r []*sarifFix) {
+	for _, f := range fixes {
+		if f.TextEdit == nil {
+			continue
+		}
+		fix := &sarifFix{
+			ArtifactChanges: []*sarifArtifactChange{{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(path)},
+				Replacements: []*sarifReplacement{{
+					DeletedRegion: sarifRegion{
+						StartLine: f.TextEdit.Line,
+						EndLine:   max(f.TextEdit.Line, f.TextEdit.End),
+					},
+					InsertedContent: &sarifMessage{Text: f.TextEdit.NewText},
+				}},
+			}},
+		}
+		if f.Message != "" {
+			fix.Description = &sarifMessage{Text: f.Message}
+		}
+		r = append(r, fix)
+	}
+	return r
+}
+
+// lintSARIF returns a SARIF run for the given lint report.
+// Files are located relative to the given directory.
+func lintSARIF(version, dir string, r *atlasexec.SummaryReport) *sarifRun {
+	run := newSARIFRun(version)
+	for _, f := range r.Files {
+		path := filepath.Join(dir, f.Name)
+		if f.Error != "" && len(f.Reports) == 0 {
+			run.add("", "", "", f.Error, true, sarifFileLocation(path, 1), nil)
+			continue
+		}
+		for _, rr := range f.Reports {
+			// Report-level fixes are attached to its first diagnostic.
+			fixes := sarifFixes(path, rr.SuggestedFixes)
+			for _, d := range rr.Diagnostics {
+				run.add(d.Code, rr.Text, "", d.Text, f.Error != "",
+					sarifFileLocation(path, diagnosticLine(f, d.Pos)),
+					append(fixes, sarifFixes(path, d.SuggestedFixes)...))
+				fixes = nil
+			}
+		}
+	}
+	return run
+}
+
+// schemaLintSARIF returns a SARIF run for the given schema lint report. Diagnostics without
+// a position are located at the schema file, or skipped if the schema is not read from a file.
+func schemaLintSARIF(version string, r *SchemaLintReport) *sarifRun {
+	run := newSARIFRun(version)
+	var file string
+	for _, u := range r.URL {
+		if p, ok := strings.CutPrefix(u, "file://"); ok {
+			file = p
+			break
+		}
+	}
+	for _, s := range r.Steps {
+		for _, d := range s.Diagnostics {
+			var loc *sarifLocation
+			switch {
+			case d.Pos != nil:
+				loc = sarifFileLocation(d.Pos.Filename, d.Pos.Start.Line)
+			case file != "":
+				loc = sarifFileLocation(file, 1)
+			default:
+				// Code scanning rejects results without a location.
+				continue
+			}
+			run.add(d.Code, s.Text, s.Desc, d.Text, s.Error, loc, nil)
+		}
+	}
+	return run
+}
+
+// writeSARIF writes the run as a SARIF log to the given path.
+// The directory of the file is created if it does not exist.
+func writeSARIF(path string, run *sarifRun) error {
+	buf, err := json.MarshalIndent(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(buf, '\n'), 0644)
+}
@@ -0,0 +1,157 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/atlasexec"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "reports", "atlas.sarif")
+	)
+	lint := &atlasexec.SummaryReport{
+		Files: []*atlasexec.FileReport{
+			{
+				Name: "1.sql",
+				Text: "CREATE TABLE t(c int);\nDROP TABLE t;\n",
+				Reports: []sqlcheck.Report{{
+					Text: "destructive changes detected",
+					Diagnostics: []sqlcheck.Diagnostic{
+						{
+							Pos:  23,
+							Text: `Dropping table "t"`,
+							Code: "DS102",
+							SuggestedFixes: []sqlcheck.SuggestedFix{{
+								Message:  "Add a pre-migration check",
+								TextEdit: &sqlcheck.TextEdit{Line: 2, End: 2, NewText: "-- atlas:nolint DS102\nDROP TABLE t;"},
+							}},
+						},
+					},
+				}},
+				Error: "destructive changes detected",
+			},
+			{Name: "2.sql", Error: "syntax error"},
+		},
+	}
+	lint.Env.Dir = "migrations"
+	require.NoError(t, writeSARIF(path, lintSARIF("v1.0.0", lint.Env.Dir, lint)))
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {
+      "name": "Atlas",
+      "version": "v1.0.0",
+      "informationUri": "https://atlasgo.io",
+      "rules": [
+        {"id": "DS102", "shortDescription": {"text": "destructive changes detected"}, "helpUri": "https://atlasgo.io/lint/analyzers#DS102"},
+        {"id": "atlas", "shortDescription": {"text": "Atlas finding"}, "helpUri": "https://atlasgo.io/lint/analyzers"}
+      ]
+    }},
+    "results": [
+      {
+        "ruleId": "DS102",
+        "ruleIndex": 0,
+        "level": "error",
+        "message": {"text": "destructive changes detected: Dropping table \"t\""},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "migrations/1.sql"}, "region": {"startLine": 2}}}],
+        "fixes": [{
+          "description": {"text": "Add a pre-migration check"},
+          "artifactChanges": [{
+            "artifactLocation": {"uri": "migrations/1.sql"},
+            "replacements": [{"deletedRegion": {"startLine": 2, "endLine": 2}, "insertedContent": {"text": "-- atlas:nolint DS102\nDROP TABLE t;"}}]
+          }]
+        }]
+      },
+      {
+        "ruleId": "atlas",
+        "ruleIndex": 1,
+        "level": "error",
+        "message": {"text": "syntax error"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "migrations/2.sql"}, "region": {"startLine": 1}}}]
+      }
+    ]
+  }]
+}`, string(buf))
+
+	// Existing files are overwritten.
+	pos := &schema.Pos{Filename: "schema.hcl"}
+	pos.Start.Line = 5
+	require.NoError(t, writeSARIF(path, schemaLintSARIF("v1.0.0", &SchemaLintReport{
+		URL: []string{"file://schema.hcl"},
+		SchemaLintReport: &atlasexec.SchemaLintReport{
+			Steps: []atlasexec.Report{{
+				Text:  "naming",
+				Desc:  "Naming conventions",
+				Error: true,
+				Diagnostics: []atlasexec.Diagnostic{
+					{Text: "Table name should use snake_case", Code: "NM101", Pos: pos},
+					{Text: "Column name should use snake_case", Code: "NM101"},
+				},
+			}},
+		},
+	})))
+	buf, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {
+      "name": "Atlas",
+      "version": "v1.0.0",
+      "informationUri": "https://atlasgo.io",
+      "rules": [
+        {"id": "NM101", "shortDescription": {"text": "naming"}, "fullDescription": {"text": "Naming conventions"}, "helpUri": "https://atlasgo.io/lint/analyzers#NM101"}
+      ]
+    }},
+    "results": [
+      {
+        "ruleId": "NM101",
+        "ruleIndex": 0,
+        "level": "error",
+        "message": {"text": "naming: Table name should use snake_case"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "schema.hcl"}, "region": {"startLine": 5}}}]
+      },
+      {
+        "ruleId": "NM101",
+        "ruleIndex": 0,
+        "level": "error",
+        "message": {"text": "naming: Column name should use snake_case"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "schema.hcl"}, "region": {"startLine": 1}}}]
+      }
+    ]
+  }]
+}`, string(buf))
+
+	// Diagnostics that cannot be located are skipped.
+	run := schemaLintSARIF("v1.0.0", &SchemaLintReport{
+		URL: []string{"mysql://localhost:3306/dev"},
+		SchemaLintReport: &atlasexec.SchemaLintReport{
+			Steps: []atlasexec.Report{{
+				Text: "naming",
+				Diagnostics: []atlasexec.Diagnostic{
+					{Text: "Table name should use snake_case", Code: "NM101", Pos: pos},
+					{Text: "Column name should use snake_case", Code: "NM101"},
+				},
+			}},
+		},
+	})
+	require.Len(t, run.Results, 1)
+	for _, r := range run.Results {
+		require.NotEmpty(t, r.Locations)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -291,6 +290,32 @@ func (t *TeamCity) SchemaApply(_ context.Context, r *atlasexec.SchemaApply) {
 	}
 }
 
+// TestResults implements TestReporter.
+func (t *TeamCity) TestResults(_ context.Context, r *TestReport) {
+	// Without parsed tests, TeamCity cannot show anything in the Tests tab.
+	if len(r.Tests) == 0 {
+		return
+	}
+	flowID := teamcity.WithFlowID(strings.ReplaceAll(r.Cmd, "/", "-"))
+	t.TestSuiteStarted(r.Cmd, flowID)
+	defer t.TestSuiteFinished(r.Cmd, flowID)
+	for _, c := range r.Tests {
+		t.TestStarted(c.Name, flowID)
+		switch c.Status {
+		case TestFail:
+			t.TestFailed(c.Name, teamcity.WithMessage(fmt.Sprintf("%s failed", c.Name)),
+				teamcity.WithDetails(c.Output), flowID)
+		case TestSkip:
+			t.TestIgnored(c.Name, teamcity.WithMessage(c.Output), flowID)
+		default:
+			if c.Output != "" {
+				t.TestStdOut(c.Name, c.Output, flowID)
+			}
+		}
+		t.TestFinished(c.Name, teamcity.WithDuration(int(c.Elapsed.Milliseconds())), flowID)
+	}
+}
+
 // planReport reports the results of a schema plan to TeamCity,
 // including lint results if present.
 func (t *TeamCity) planReport(r *atlasexec.SchemaPlan, tagPrefix string) {
@@ -445,7 +470,7 @@ func (t *TeamCity) GetType() atlasexec.TriggerType {
 }
 
 // GetTriggerContext implements [Action].
-func (t *TeamCity) GetTriggerContext(context.Context) (*TriggerContext, error) {
+func (t *TeamCity) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
 	props, err := t.buildProperties()
 	if err != nil {
 		return nil, err
@@ -470,52 +495,8 @@ func (t *TeamCity) GetTriggerContext(context.Context) (*TriggerContext, error) {
 		}
 	}
 	// Detect SCM provider by parsing the URL and checking the hostname
-	switch u, err := url.Parse(tc.RepoURL); {
-	case err != nil:
-		return nil, fmt.Errorf("parsing repo URL %q: %w", tc.RepoURL, err)
-	case u.Host != "":
-		switch h := strings.ToLower(u.Hostname()); {
-		case h == "github.com" || strings.HasSuffix(h, ".github.com"):
-			tc.SCMType = atlasexec.SCMTypeGithub
-			tc.SCMClient = func() (SCMClient, error) {
-				token := t.getenv("GITHUB_TOKEN")
-				if token == "" {
-					t.Warningf("GITHUB_TOKEN is not set, the action may not have all the permissions")
-				}
-				return NewGitHubClient(tc.Repo, t.getenv("GITHUB_API_URL"), token)
-			}
-			if tc.PullRequest != nil {
-				tc.PullRequest.URL = fmt.Sprintf("%s/pull/%d", strings.TrimSuffix(tc.RepoURL, ".git"), tc.PullRequest.Number)
-			}
-		case h == "gitlab.com" || strings.HasSuffix(h, ".gitlab.com"):
-			tc.SCMType = atlasexec.SCMTypeGitlab
-			tc.SCMClient = func() (SCMClient, error) {
-				token := t.getenv("GITLAB_TOKEN")
-				if token == "" {
-					t.Warningf("GITLAB_TOKEN is not set, the action may not have all the permissions")
-				}
-				return NewGitLabClient(tc.Repo, t.getenv("CI_API_V4_URL"), token)
-			}
-			if tc.PullRequest != nil {
-				tc.PullRequest.URL = fmt.Sprintf("%s/-/merge_requests/%d", strings.TrimSuffix(tc.RepoURL, ".git"), tc.PullRequest.Number)
-			}
-		case h == "bitbucket.org":
-			tc.SCMType = atlasexec.SCMTypeBitbucket
-			tc.SCMClient = func() (SCMClient, error) {
-				token := t.getenv("BITBUCKET_ACCESS_TOKEN")
-				if token == "" {
-					t.Warningf("BITBUCKET_ACCESS_TOKEN is not set, the action may not have all the permissions")
-				}
-				return NewBitbucketClient(
-					t.getenv("BITBUCKET_WORKSPACE"),
-					t.getenv("BITBUCKET_REPO_SLUG"),
-					token,
-				)
-			}
-			if tc.PullRequest != nil {
-				tc.PullRequest.URL = fmt.Sprintf("%s/pull-requests/%d", strings.TrimSuffix(tc.RepoURL, ".git"), tc.PullRequest.Number)
-			}
-		}
+	if err := tc.detectSCM(ctx); err != nil {
+		return nil, err
 	}
 	return tc, nil
 }
@@ -565,6 +546,7 @@ func durationMs(start, end time.Time) int64 {
 }
 
 var (
-	_ Action   = (*TeamCity)(nil)
-	_ Reporter = (*TeamCity)(nil)
+	_ Action       = (*TeamCity)(nil)
+	_ Reporter     = (*TeamCity)(nil)
+	_ TestReporter = (*TeamCity)(nil)
 )
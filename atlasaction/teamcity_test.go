@@ -7,6 +7,9 @@ package atlasaction_test
 import (
 	"bytes"
 	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -89,6 +92,127 @@ vcsroot.url=` + tt.repoURL
 	}
 }
 
+func TestTeamCity_SelfManagedSCM(t *testing.T) {
+	server := func(path string, h http.HandlerFunc) *httptest.Server {
+		m := http.NewServeMux()
+		m.HandleFunc("GET "+path, h)
+		srv := httptest.NewServer(m)
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	var (
+		gitlab = server("/api/v4/version", func(w http.ResponseWriter, r *http.Request) {
+			// Credentials are never sent to probed hosts.
+			require.Empty(t, r.Header.Get("PRIVATE-TOKEN"))
+			w.Header().Set("X-Gitlab-Meta", `{"correlation_id":"1"}`)
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		github = server("/api/v3/meta", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-GitHub-Enterprise-Version", "3.14.0")
+			w.Write([]byte(`{"installed_version":"3.14.0"}`))
+		})
+		bitbucket = server("/rest/api/1.0/application-properties", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"version":"8.19.0","displayName":"Bitbucket"}`))
+		})
+		unknown  = server("/", http.NotFound)
+		redirect = server("/", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, gitlab.URL+r.URL.Path, http.StatusFound)
+		})
+	)
+	tests := []struct {
+		name    string
+		repoURL string
+		env     map[string]string
+		pr      bool
+		scm     atlasexec.SCMType
+		prURL   string
+		err     string // SCM client error.
+	}{
+		{
+			name:    "explicit provider",
+			repoURL: "https://git.example.com/ariga/atlas.git",
+			env:     map[string]string{"ATLAS_SCM_PROVIDER": "gitlab", "ATLAS_SCM_API_URL": "https://git.example.com/api/v4"},
+			pr:      true,
+			scm:     atlasexec.SCMTypeGitlab,
+			prURL:   "https://git.example.com/ariga/atlas/-/merge_requests/42",
+		},
+		{
+			name:    "explicit provider without pull request",
+			repoURL: "https://git.example.com/ariga/atlas.git",
+			env:     map[string]string{"ATLAS_SCM_PROVIDER": "GitHub"},
+			scm:     atlasexec.SCMTypeGithub,
+		},
+		{
+			name:    "probe GitLab",
+			repoURL: gitlab.URL + "/ariga/atlas.git",
+			env:     map[string]string{"GITLAB_TOKEN": "secret"},
+			pr:      true,
+			scm:     atlasexec.SCMTypeGitlab,
+			prURL:   gitlab.URL + "/ariga/atlas/-/merge_requests/42",
+		},
+		{
+			name:    "probe GitHub Enterprise",
+			repoURL: github.URL + "/ariga/atlas.git",
+			pr:      true,
+			scm:     atlasexec.SCMTypeGithub,
+			prURL:   github.URL + "/ariga/atlas/pull/42",
+		},
+		{
+			name:    "probe Bitbucket Data Center",
+			repoURL: bitbucket.URL + "/scm/ariga/atlas.git",
+			pr:      true,
+			scm:     atlasexec.SCMTypeBitbucket,
+			prURL:   bitbucket.URL + "/projects/ARIGA/repos/atlas/pull-requests/42",
+			err:     "Bitbucket Data Center is not supported, only Bitbucket Cloud (bitbucket.org)",
+		},
+		{
+			name:    "no probing without pull request",
+			repoURL: gitlab.URL + "/ariga/atlas.git",
+		},
+		{
+			name:    "redirects to other hosts are not followed",
+			repoURL: redirect.URL + "/ariga/atlas.git",
+			pr:      true,
+		},
+		{
+			name:    "unknown provider",
+			repoURL: unknown.URL + "/ariga/atlas.git",
+			pr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "teamcity.projectName=ariga/atlas\nbuild.vcs.number=abc123\nvcsroot.url=" + tt.repoURL + "\n"
+			if tt.pr {
+				content += "teamcity.pullRequest.number=42\n"
+			}
+			propsFile := filepath.Join(t.TempDir(), "build.properties")
+			require.NoError(t, os.WriteFile(propsFile, []byte(content), 0600))
+			env := map[string]string{"TEAMCITY_BUILD_PROPERTIES_FILE": propsFile}
+			maps.Copy(env, tt.env)
+			a := atlasaction.NewTeamCity(func(k string) string { return env[k] }, &bytes.Buffer{})
+			tc, err := a.GetTriggerContext(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tt.scm, tc.SCMType)
+			if tt.prURL != "" {
+				require.Equal(t, tt.prURL, tc.PullRequest.URL)
+			}
+			if tt.err != "" {
+				_, err := tc.SCMClient()
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+
+	propsFile := filepath.Join(t.TempDir(), "build.properties")
+	require.NoError(t, os.WriteFile(propsFile, []byte("vcsroot.url=https://git.example.com/ariga/atlas.git\n"), 0600))
+	a := atlasaction.NewTeamCity(func(k string) string {
+		return map[string]string{"TEAMCITY_BUILD_PROPERTIES_FILE": propsFile, "ATLAS_SCM_PROVIDER": "svn"}[k]
+	}, &bytes.Buffer{})
+	_, err := a.GetTriggerContext(context.Background())
+	require.EqualError(t, err, `unsupported ATLAS_SCM_PROVIDER "svn", expected github, gitlab or bitbucket`)
+}
+
 func TestTeamCity_SchemaLint(t *testing.T) {
 	var buf bytes.Buffer
 	tc := atlasaction.NewTeamCity(func(string) string { return "" }, &buf)
@@ -138,6 +262,29 @@ func TestTeamCity_SchemaLint(t *testing.T) {
 `, buf.String())
 }
 
+func TestTeamCity_TestResults(t *testing.T) {
+	var buf bytes.Buffer
+	tc := atlasaction.NewTeamCity(func(string) string { return "" }, &buf)
+	tc.TestResults(context.Background(), atlasaction.NewTestReport(atlasaction.CmdSchemaTest, `-- PASS: seed (1ms)
+-- FAIL: positive (2ms)
+    schema.test.hcl:4:
+        Error: check constraint "positive" failed
+-- SKIP: legacy (0s)
+FAIL
+`, nil))
+	require.Equal(t, `##teamcity[testSuiteStarted flowId='schema-test' name='schema/test']
+##teamcity[testStarted flowId='schema-test' name='seed']
+##teamcity[testFinished duration='1' flowId='schema-test' name='seed']
+##teamcity[testStarted flowId='schema-test' name='positive']
+##teamcity[testFailed details='schema.test.hcl:4:|n    Error: check constraint "positive" failed' flowId='schema-test' message='positive failed' name='positive']
+##teamcity[testFinished duration='2' flowId='schema-test' name='positive']
+##teamcity[testStarted flowId='schema-test' name='legacy']
+##teamcity[testIgnored flowId='schema-test' message='' name='legacy']
+##teamcity[testFinished duration='0' flowId='schema-test' name='legacy']
+##teamcity[testSuiteFinished flowId='schema-test' name='schema/test']
+`, buf.String())
+}
+
 func TestTeamCity(t *testing.T) {
 	wd, err := os.Getwd()
 	require.NoError(t, err)
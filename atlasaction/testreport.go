@@ -0,0 +1,119 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/atlasexec"
+)
+
+type (
+	// TestReport contains the results of the test actions, e.g. "migrate/test".
+	TestReport struct {
+		Cmd    string      // The action that ran the tests.
+		Output string      // Raw output of the command.
+		Error  string      // Error of the command, if failed.
+		Tests  []*TestCase // Parsed test cases.
+	}
+	// TestCase is a single test case in the report.
+	TestCase struct {
+		Name    string
+		Status  string // One of TestPass, TestFail or TestSkip.
+		Elapsed time.Duration
+		Output  string // Output of the test, e.g. the failure details.
+	}
+)
+
+// Test case statuses.
+const (
+	TestPass = "PASS"
+	TestFail = "FAIL"
+	TestSkip = "SKIP"
+)
+
+// testLineRe matches the result line of a test case in the
+// output of Atlas test commands. e.g. "--- FAIL: users (1.2ms)".
+var testLineRe = regexp.MustCompile(`^\s*-{2,3} (PASS|FAIL|SKIP): (.+?)(?: \(([^()]*)\))?$`)
+
+// NewTestReport parses the output of the given test command into a report.
+// The stdout of the command is extracted from the error, if the tests failed.
+func NewTestReport(cmd, out string, err error) *TestReport {
+	r := &TestReport{Cmd: cmd, Output: out}
+	if err != nil {
+		r.Error = strings.TrimSpace(err.Error())
+		if e := (*atlasexec.Error)(nil); errors.As(err, &e) && r.Output == "" {
+			r.Output = e.Stdout
+		}
+	}
+	var (
+		c     *TestCase
+		lines []string
+	)
+	flush := func() {
+		if c != nil {
+			c.Output = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+		}
+		c, lines = nil, nil
+	}
+	for _, line := range strings.Split(r.Output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := testLineRe.FindStringSubmatch(line); m != nil {
+			flush()
+			c = &TestCase{Name: m[2], Status: m[1]}
+			c.Elapsed, _ = time.ParseDuration(m[3])
+			r.Tests = append(r.Tests, c)
+			continue
+		}
+		// Details of the test are indented under its result line.
+		switch trimmed := strings.TrimLeft(line, " \t"); {
+		case c == nil:
+		case trimmed == "":
+			lines = append(lines, "")
+		case trimmed != line:
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(line, "\t"), "    "))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return r
+}
+
+// Count returns the number of test cases with the given status.
+func (r *TestReport) Count(status string) (n int) {
+	for _, c := range r.Tests {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// reportTests reports the results of the test command to the
+// action and writes the JUnit report if requested. Reporting errors
+// are logged, so they do not hide the result of the command.
+func (a *Actions) reportTests(ctx context.Context, cmd, out string, err error) {
+	r := NewTestReport(cmd, out, err)
+	if name := a.GetInput("junit-file"); name != "" {
+		// The file is owned by the user, and is overwritten by each run.
+		if err := writeJUnit(name, testJUnit(r)); err != nil {
+			a.Errorf("failed to write JUnit file: %v", err)
+		}
+	}
+	if len(r.Tests) > 0 {
+		a.SetOutput("passed", strconv.Itoa(r.Count(TestPass)))
+		a.SetOutput("failed", strconv.Itoa(r.Count(TestFail)))
+		a.SetOutput("skipped", strconv.Itoa(r.Count(TestSkip)))
+	}
+	if tr, ok := a.Action.(TestReporter); ok {
+		tr.TestResults(ctx, r)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestReport(t *testing.T) {
+	r := atlasaction.NewTestReport(atlasaction.CmdMigrateTest, `-- PASS: migrate/20240101000000.sql (1.5ms)
+--- PASS: seed/1 (500µs)
+-- FAIL: users (2ms)
+    migrate.test.hcl:10:
+        Error: expected 1 row, got 0
+
+-- SKIP: legacy
+FAIL
+`, nil)
+	require.Equal(t, []*atlasaction.TestCase{
+		{Name: "migrate/20240101000000.sql", Status: atlasaction.TestPass, Elapsed: 1500 * time.Microsecond},
+		{Name: "seed/1", Status: atlasaction.TestPass, Elapsed: 500 * time.Microsecond},
+		{Name: "users", Status: atlasaction.TestFail, Elapsed: 2 * time.Millisecond, Output: "migrate.test.hcl:10:\n    Error: expected 1 row, got 0"},
+		{Name: "legacy", Status: atlasaction.TestSkip},
+	}, r.Tests)
+	require.Equal(t, 2, r.Count(atlasaction.TestPass))
+	require.Equal(t, 1, r.Count(atlasaction.TestFail))
+	require.Equal(t, 1, r.Count(atlasaction.TestSkip))
+
+	// The output of failed runs is extracted from the error.
+	r = atlasaction.NewTestReport(atlasaction.CmdSchemaTest, "", &atlasexec.Error{
+		Stdout: "-- FAIL: users (1ms)\n    schema.test.hcl:1: failed\n",
+		Stderr: "Error: 1 test failed",
+	})
+	require.Equal(t, "Error: 1 test failed", r.Error)
+	require.Len(t, r.Tests, 1)
+	require.Equal(t, "schema.test.hcl:1: failed", r.Tests[0].Output)
+}
+
+func TestActions_TestResults(t *testing.T) {
+	junit := filepath.Join(t.TempDir(), "junit.xml")
+	// Reports of previous runs are overwritten.
+	require.NoError(t, os.WriteFile(junit, []byte("stale"), 0644))
+	act := &mockAction{
+		inputs: map[string]string{"junit-file": junit},
+	}
+	cli := &mockAtlas{
+		scriptTest: func(context.Context, *atlasexec.ScriptTestParams) (string, error) {
+			return "", &atlasexec.Error{
+				Stdout: "-- PASS: seed (1ms)\n-- FAIL: users (2ms)\n    script.test.hcl:10: expected 1 row\n-- SKIP: legacy (0s)\nFAIL\n",
+				Stderr: "Error: 1 test failed",
+			}
+		},
+	}
+	acts, err := atlasaction.New(atlasaction.WithAction(act), atlasaction.WithAtlas(cli))
+	require.NoError(t, err)
+	require.ErrorContains(t, acts.Run(context.Background(), atlasaction.CmdScriptTest), "`atlas script test` completed with errors")
+	require.Equal(t, map[string]string{"passed": "1", "failed": "1", "skipped": "1"}, act.output)
+	buf, err := os.ReadFile(junit)
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1">
+  <testsuite name="script/test" tests="3" failures="1" skipped="1" time="0.003">
+    <testcase name="seed" classname="script/test" time="0.001"></testcase>
+    <testcase name="users" classname="script/test" time="0.002">
+      <failure message="users failed" type="error">script.test.hcl:10: expected 1 row</failure>
+    </testcase>
+    <testcase name="legacy" classname="script/test" time="0.000">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(buf))
+
+	// Failing to write the report does not hide the test results.
+	var out bytes.Buffer
+	act = &mockAction{
+		inputs: map[string]string{"junit-file": filepath.Join(junit, "junit.xml")},
+		logger: slog.New(slog.NewTextHandler(&out, nil)),
+	}
+	acts, err = atlasaction.New(atlasaction.WithAction(act), atlasaction.WithAtlas(cli))
+	require.NoError(t, err)
+	require.ErrorContains(t, acts.Run(context.Background(), atlasaction.CmdScriptTest), "`atlas script test` completed with errors")
+	require.Equal(t, map[string]string{"passed": "1", "failed": "1", "skipped": "1"}, act.output)
+	require.Contains(t, out.String(), "failed to write JUnit file")
+}
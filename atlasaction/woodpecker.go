@@ -0,0 +1,201 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/atlasexec"
+)
+
+// Woodpecker is an implementation of the Action interface for Woodpecker CI and Drone CI.
+type Woodpecker struct {
+	*coloredLogger
+	getenv func(string) string
+}
+
+var _ Action = (*Woodpecker)(nil)
+
+// NewWoodpecker returns a new Action for Woodpecker CI and Drone CI.
+func NewWoodpecker(getenv func(string) string, w io.Writer) *Woodpecker {
+	return &Woodpecker{getenv: getenv, coloredLogger: &coloredLogger{w}}
+}
+
+// GetType implements the Action interface.
+func (a *Woodpecker) GetType() atlasexec.TriggerType {
+	if a.getenv("CI") == "woodpecker" {
+		return atlasexec.TriggerType("WOODPECKER")
+	}
+	return atlasexec.TriggerType("DRONE")
+}
+
+// Getenv implements Action.
+func (a *Woodpecker) Getenv(key string) string {
+	return a.getenv(key)
+}
+
+// GetInput implements the Action interface.
+func (a *Woodpecker) GetInput(name string) string {
+	if v := a.getenv(toInputVarName(name)); v != "" {
+		return strings.TrimSpace(v)
+	}
+	// When running as a plugin, the settings are
+	// passed as environment variables with the PLUGIN_ prefix:
+	// ```yaml
+	// steps:
+	//   - name: lint
+	//     image: arigaio/atlas-action
+	//     settings:
+	//       action: migrate/lint
+	//       dir: file://migrations
+	// ```
+	return strings.TrimSpace(a.getenv(toEnvName("PLUGIN_" + name)))
+}
+
+// SetOutput implements the Action interface.
+func (a *Woodpecker) SetOutput(name, value string) {
+	// Steps share the workspace, so the outputs are written
+	// to a file that can be sourced by the next steps.
+	// e.g:
+	// ```shell
+	// source .atlas-action/outputs.sh
+	// ```
+	dir := filepath.Join(a.env("CI_WORKSPACE", "DRONE_WORKSPACE"), ".atlas-action")
+	if out := a.getenv("ATLAS_OUTPUT_DIR"); out != "" {
+		// The user can set the output directory using
+		// the ATLAS_OUTPUT_DIR environment variable.
+		dir = out
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		a.Errorf("failed to create output directory %s: %v", dir, err)
+		return
+	}
+	outputs := filepath.Join(dir, "outputs.sh")
+	err := fprintln(outputs,
+		"export", toOutputVar(a.getenv("ATLAS_ACTION_COMMAND"), name, value))
+	if err != nil {
+		a.Errorf("failed to write output to file %s: %v", outputs, err)
+	}
+}
+
+// GetTriggerContext implements the Action interface.
+// https://woodpecker-ci.org/docs/usage/environment
+// https://docs.drone.io/pipeline/environment/reference/
+func (a *Woodpecker) GetTriggerContext(ctx context.Context) (*TriggerContext, error) {
+	tc := &TriggerContext{
+		Act:           a,
+		Repo:          a.env("CI_REPO", "DRONE_REPO"),
+		RepoURL:       a.env("CI_REPO_URL", "DRONE_REPO_LINK"),
+		DefaultBranch: a.env("CI_REPO_DEFAULT_BRANCH", "DRONE_REPO_BRANCH"),
+		Branch:        a.env("CI_COMMIT_BRANCH", "DRONE_BRANCH"),
+		Commit:        a.env("CI_COMMIT_SHA", "DRONE_COMMIT_SHA"),
+	}
+	if tc.Commit == "" {
+		return nil, fmt.Errorf("missing CI_COMMIT_SHA environment variable")
+	}
+	if u := a.env("CI_COMMIT_AUTHOR", "DRONE_COMMIT_AUTHOR"); u != "" {
+		tc.Actor = &Actor{Name: u}
+	}
+	if pr := a.env("CI_COMMIT_PULL_REQUEST", "DRONE_PULL_REQUEST"); pr != "" {
+		n, err := strconv.Atoi(pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pull request number %q: %w", pr, err)
+		}
+		tc.PullRequest = &PullRequest{
+			Number: n,
+			Commit: tc.Commit,
+		}
+		if b := a.env("CI_COMMIT_SOURCE_BRANCH", "DRONE_SOURCE_BRANCH"); b != "" {
+			tc.Branch = b
+		}
+		if b := a.env("CI_COMMIT_TARGET_BRANCH", "DRONE_TARGET_BRANCH"); b != "" {
+			tc.DefaultBranch = b
+		}
+	}
+	forgeURL := strings.TrimSuffix(a.getenv("CI_FORGE_URL"), "/")
+	if forgeURL == "" {
+		if u, err := url.Parse(tc.RepoURL); err == nil && u.Host != "" {
+			forgeURL = u.Scheme + "://" + u.Host
+		}
+	}
+	// Woodpecker exposes the forge type. Drone does not, so we fall back
+	// to detecting the SCM provider from the repository URL.
+	switch a.getenv("CI_FORGE_TYPE") {
+	case "github":
+		tc.SCMType = atlasexec.SCMTypeGithub
+		tc.SCMClient = func() (SCMClient, error) {
+			token := a.getenv("GITHUB_TOKEN")
+			if token == "" && !hasGitHubApp(a.getenv) {
+				a.Warningf("GITHUB_TOKEN is not set, the action may not have all the permissions")
+			}
+			apiURL := a.getenv("GITHUB_API_URL")
+			if apiURL == "" && forgeURL != "" && forgeURL != "https://github.com" {
+				// GitHub Enterprise Server.
+				apiURL = forgeURL + "/api/v3"
+			}
+			return newGitHubClient(a.getenv, tc.Repo, apiURL, token)
+		}
+		if pr := tc.PullRequest; pr != nil {
+			pr.URL = fmt.Sprintf("%s/pull/%d", tc.RepoURL, pr.Number)
+		}
+	case "gitlab":
+		tc.SCMType = atlasexec.SCMTypeGitlab
+		tc.SCMClient = func() (SCMClient, error) {
+			token := a.getenv("GITLAB_TOKEN")
+			if token == "" {
+				a.Warningf("GITLAB_TOKEN is not set, the action may not have all the permissions")
+			}
+			apiURL := a.getenv("CI_API_V4_URL")
+			if apiURL == "" && forgeURL != "" {
+				apiURL = forgeURL + "/api/v4"
+			}
+			// The project can be identified by its URL-encoded path.
+			return NewGitLabClient(url.PathEscape(tc.Repo), apiURL, token)
+		}
+		if pr := tc.PullRequest; pr != nil {
+			pr.URL = fmt.Sprintf("%s/-/merge_requests/%d", tc.RepoURL, pr.Number)
+		}
+	case "gitea", "forgejo":
+		// Atlas Cloud has no SCM type for Gitea, see Gitea.GetTriggerContext.
+		tc.SCMType = atlasexec.SCMTypeGithub
+		tc.SCMClient = func() (SCMClient, error) {
+			token := a.getenv("GITEA_TOKEN")
+			if token == "" {
+				a.Warningf("GITEA_TOKEN is not set, the action may not have all the permissions")
+			}
+			var apiURL string
+			if forgeURL != "" {
+				apiURL = forgeURL + "/api/v1"
+			}
+			return NewGiteaClient(tc.Repo, apiURL, token)
+		}
+		if pr := tc.PullRequest; pr != nil {
+			pr.URL = fmt.Sprintf("%s/pulls/%d", tc.RepoURL, pr.Number)
+		}
+	default:
+		if err := tc.detectSCM(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return tc, nil
+}
+
+// env returns the value of the first non-empty environment variable.
+// Woodpecker variables come first, followed by their Drone equivalents.
+func (a *Woodpecker) env(keys ...string) string {
+	for _, k := range keys {
+		if v := a.getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
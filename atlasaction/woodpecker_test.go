@@ -0,0 +1,145 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package atlasaction_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ariga.io/atlas-action/atlasaction"
+	"ariga.io/atlas/atlasexec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWoodpecker_GetTriggerContext(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *atlasaction.TriggerContext
+	}{
+		{
+			name: "woodpecker gitea pull request",
+			env: map[string]string{
+				"CI":                      "woodpecker",
+				"CI_COMMIT_SHA":           "abc123",
+				"CI_COMMIT_BRANCH":        "main",
+				"CI_COMMIT_SOURCE_BRANCH": "feature",
+				"CI_COMMIT_TARGET_BRANCH": "main",
+				"CI_COMMIT_PULL_REQUEST":  "3",
+				"CI_COMMIT_AUTHOR":        "a8m",
+				"CI_REPO":                 "ariga/atlas-action",
+				"CI_REPO_URL":             "https://codeberg.org/ariga/atlas-action",
+				"CI_REPO_DEFAULT_BRANCH":  "main",
+				"CI_FORGE_TYPE":           "forgejo",
+				"CI_FORGE_URL":            "https://codeberg.org",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType:       atlasexec.SCMTypeGithub,
+				Repo:          "ariga/atlas-action",
+				RepoURL:       "https://codeberg.org/ariga/atlas-action",
+				DefaultBranch: "main",
+				Branch:        "feature",
+				Commit:        "abc123",
+				Actor:         &atlasaction.Actor{Name: "a8m"},
+				PullRequest: &atlasaction.PullRequest{
+					Number: 3,
+					URL:    "https://codeberg.org/ariga/atlas-action/pulls/3",
+					Commit: "abc123",
+				},
+			},
+		},
+		{
+			name: "woodpecker gitlab push",
+			env: map[string]string{
+				"CI":               "woodpecker",
+				"CI_COMMIT_SHA":    "abc123",
+				"CI_COMMIT_BRANCH": "main",
+				"CI_REPO":          "ariga/atlas-action",
+				"CI_REPO_URL":      "https://git.example.com/ariga/atlas-action",
+				"CI_FORGE_TYPE":    "gitlab",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType: atlasexec.SCMTypeGitlab,
+				Repo:    "ariga/atlas-action",
+				RepoURL: "https://git.example.com/ariga/atlas-action",
+				Branch:  "main",
+				Commit:  "abc123",
+			},
+		},
+		{
+			name: "drone github pull request",
+			env: map[string]string{
+				"DRONE":               "true",
+				"DRONE_COMMIT_SHA":    "abc123",
+				"DRONE_BRANCH":        "main",
+				"DRONE_SOURCE_BRANCH": "feature",
+				"DRONE_TARGET_BRANCH": "main",
+				"DRONE_PULL_REQUEST":  "5",
+				"DRONE_REPO":          "ariga/atlas-action",
+				"DRONE_REPO_LINK":     "https://github.com/ariga/atlas-action",
+				"DRONE_REPO_BRANCH":   "master",
+			},
+			want: &atlasaction.TriggerContext{
+				SCMType:       atlasexec.SCMTypeGithub,
+				Repo:          "ariga/atlas-action",
+				RepoURL:       "https://github.com/ariga/atlas-action",
+				DefaultBranch: "main",
+				Branch:        "feature",
+				Commit:        "abc123",
+				PullRequest: &atlasaction.PullRequest{
+					Number: 5,
+					URL:    "https://github.com/ariga/atlas-action/pull/5",
+					Commit: "abc123",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := atlasaction.NewWoodpecker(func(k string) string { return tt.env[k] }, &bytes.Buffer{})
+			tc, err := a.GetTriggerContext(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, tc.SCMClient)
+			_, err = tc.SCMClient()
+			require.NoError(t, err)
+			tt.want.Act, tc.SCMClient = a, nil
+			require.Equal(t, tt.want, tc)
+		})
+	}
+	t.Run("drone pull request on unknown SCM", func(t *testing.T) {
+		// E.g. self-hosted Gitea, Gogs or Bitbucket Data Center servers.
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+		env := map[string]string{
+			"DRONE":              "true",
+			"DRONE_COMMIT_SHA":   "abc123",
+			"DRONE_PULL_REQUEST": "5",
+			"DRONE_REPO":         "ariga/atlas-action",
+			"DRONE_REPO_LINK":    srv.URL + "/ariga/atlas-action",
+		}
+		a := atlasaction.NewWoodpecker(func(k string) string { return env[k] }, &bytes.Buffer{})
+		tc, err := a.GetTriggerContext(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, tc.SCMType)
+		require.Equal(t, 5, tc.PullRequest.Number)
+		_, err = tc.SCMClient()
+		require.EqualError(t, err, "unable to detect the SCM provider of the repository, set ATLAS_SCM_PROVIDER")
+	})
+}
+
+func TestWoodpecker_GetInput(t *testing.T) {
+	env := map[string]string{
+		"ATLAS_INPUT_DIR": "file://migrations",
+		"PLUGIN_DIR":      "file://ignored",
+		"PLUGIN_DEV_URL":  "sqlite://dev?mode=memory",
+	}
+	a := atlasaction.NewWoodpecker(func(k string) string { return env[k] }, &bytes.Buffer{})
+	require.Equal(t, "file://migrations", a.GetInput("dir"))
+	require.Equal(t, "sqlite://dev?mode=memory", a.GetInput("dev-url"))
+	require.Empty(t, a.GetInput("env"))
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"net/url"
+	"strings"
 
+	"ariga.io/atlas-action/internal/httpretry"
 	"golang.org/x/oauth2"
 )
 
@@ -23,8 +25,24 @@ type (
 	}
 	// PullRequest is the Azure DevOps pull request.
 	PullRequest struct {
-		ID    int    `json:"pullRequestId"`
-		Title string `json:"title"`
+		ID                    int    `json:"pullRequestId"`
+		Title                 string `json:"title"`
+		Description           string `json:"description"`
+		SourceRefName         string `json:"sourceRefName"`
+		TargetRefName         string `json:"targetRefName"`
+		LastMergeSourceCommit struct {
+			CommitID string `json:"commitId"`
+		} `json:"lastMergeSourceCommit"`
+		Repository struct {
+			WebURL string `json:"webUrl"`
+		} `json:"repository"`
+	}
+	// CreatePullRequestRequest represents the request body for creating a pull request.
+	CreatePullRequestRequest struct {
+		SourceRefName string `json:"sourceRefName"`
+		TargetRefName string `json:"targetRefName"`
+		Title         string `json:"title"`
+		Description   string `json:"description,omitempty"`
 	}
 	// Comment is the Azure DevOps comment.
 	Comment struct {
@@ -36,9 +54,38 @@ type (
 	}
 	// CommentThread represents a comment thread in Azure DevOps.
 	CommentThread struct {
-		ID       int       `json:"id"`
-		Comments []Comment `json:"comments"`
-		Status   string    `json:"status"`
+		ID            int            `json:"id"`
+		Comments      []Comment      `json:"comments"`
+		Status        string         `json:"status"`
+		ThreadContext *ThreadContext `json:"threadContext,omitempty"`
+	}
+	// ThreadContext is the file location of a comment thread.
+	ThreadContext struct {
+		FilePath       string        `json:"filePath"`
+		RightFileStart *FilePosition `json:"rightFileStart,omitempty"`
+		RightFileEnd   *FilePosition `json:"rightFileEnd,omitempty"`
+	}
+	// FilePosition is a position in a file.
+	FilePosition struct {
+		Line   int `json:"line"`
+		Offset int `json:"offset"`
+	}
+	// PullRequestThreadContext is the pull request iteration a comment thread refers to.
+	PullRequestThreadContext struct {
+		ChangeTrackingID int              `json:"changeTrackingId,omitempty"`
+		IterationContext IterationContext `json:"iterationContext"`
+	}
+	// IterationContext is the range of iterations a comment thread is compared against.
+	IterationContext struct {
+		FirstComparingIteration  int `json:"firstComparingIteration"`
+		SecondComparingIteration int `json:"secondComparingIteration"`
+	}
+	// IterationChange is a file change in a pull request iteration.
+	IterationChange struct {
+		ChangeTrackingID int `json:"changeTrackingId"`
+		Item             struct {
+			Path string `json:"path"`
+		} `json:"item"`
 	}
 	// CreateCommentRequest represents the request body for creating a comment.
 	CreateCommentRequest struct {
@@ -46,8 +93,10 @@ type (
 	}
 	// CreateCommentThreadRequest represents the request body for creating a comment thread.
 	CreateCommentThreadRequest struct {
-		Comments []CreateCommentRequest `json:"comments"`
-		Status   string                 `json:"status"`
+		Comments                 []CreateCommentRequest    `json:"comments"`
+		Status                   string                    `json:"status"`
+		ThreadContext            *ThreadContext            `json:"threadContext,omitempty"`
+		PullRequestThreadContext *PullRequestThreadContext `json:"pullRequestThreadContext,omitempty"`
 	}
 	// UpdateCommentRequest represents the request body for updating a comment.
 	UpdateCommentRequest struct {
@@ -57,9 +106,18 @@ type (
 	ClientOption func(*Client) error
 )
 
+// Comment thread statuses.
+const (
+	ThreadStatusActive = "active"
+	ThreadStatusFixed  = "fixed"
+)
+
 // DefaultBaseURL is the default base URL for the Azure DevOps API.
 const DefaultBaseURL = "https://dev.azure.com"
 
+// iterationChangesPageSize is the number of changes requested per page.
+const iterationChangesPageSize = 100
+
 // WithToken returns a ClientOption that sets the token for the client.
 func WithToken(t *oauth2.Token) ClientOption {
 	return func(c *Client) error {
@@ -89,7 +147,7 @@ func NewClient(org, project, repo string, opts ...ClientOption) (*Client, error)
 		org:     org,
 		project: project,
 		repo:    repo,
-		client:  &http.Client{Timeout: time.Second * 30},
+		client:  httpretry.NewClient(nil),
 		baseURL: DefaultBaseURL,
 	}
 	for _, opt := range opts {
@@ -124,16 +182,49 @@ func (c *Client) PullRequest(ctx context.Context, prID int) (*PullRequest, error
 	return &pullRequest, nil
 }
 
+// CreatePullRequest creates a pull request from the source ref into the target ref.
+// Branch names are converted to refs, e.g. "main" becomes "refs/heads/main".
+func (c *Client) CreatePullRequest(ctx context.Context, source, target, title, description string) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests?api-version=7.1-preview.1",
+		c.baseURL, c.org, c.project, c.repo)
+	res, err := c.json(ctx, http.MethodPost, url, CreatePullRequestRequest{
+		SourceRefName: branchRef(source),
+		TargetRefName: branchRef(target),
+		Title:         title,
+		Description:   description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response for pull request creation: %w", err)
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %d when creating pull request. body: %s", res.StatusCode, string(buf))
+	}
+	var pullRequest PullRequest
+	if err = json.Unmarshal(buf, &pullRequest); err != nil {
+		return nil, fmt.Errorf("parsing pull request response: %w", err)
+	}
+	return &pullRequest, nil
+}
+
 // AddComment adds a comment to a pull request by creating a new comment thread.
 func (c *Client) AddComment(ctx context.Context, prID int, content string) (*CommentThread, error) {
-	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads?api-version=7.1-preview.1",
-		c.baseURL, c.org, c.project, c.repo, prID)
-	reqBody := CreateCommentThreadRequest{
+	return c.CreateThread(ctx, prID, &CreateCommentThreadRequest{
 		Comments: []CreateCommentRequest{
 			{Content: content},
 		},
-		Status: "active",
-	}
+		Status: ThreadStatusActive,
+	})
+}
+
+// CreateThread creates a new comment thread on a pull request.
+func (c *Client) CreateThread(ctx context.Context, prID int, reqBody *CreateCommentThreadRequest) (*CommentThread, error) {
+	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads?api-version=7.1-preview.1",
+		c.baseURL, c.org, c.project, c.repo, prID)
 	res, err := c.json(ctx, http.MethodPost, url, reqBody)
 	if err != nil {
 		return nil, err
@@ -153,6 +244,73 @@ func (c *Client) AddComment(ctx context.Context, prID int, content string) (*Com
 	return &thread, nil
 }
 
+// UpdateThreadStatus updates the status of a comment thread, e.g. "fixed".
+func (c *Client) UpdateThreadStatus(ctx context.Context, prID, threadID int, status string) error {
+	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads/%d?api-version=7.1-preview.1",
+		c.baseURL, c.org, c.project, c.repo, prID, threadID)
+	res, err := c.json(ctx, http.MethodPatch, url, map[string]string{"status": status})
+	if err != nil {
+		return fmt.Errorf("updating status of thread %d for pull request %d: %w", threadID, prID, err)
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("reading response for thread update: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d when updating thread. body: %s", res.StatusCode, string(buf))
+	}
+	return nil
+}
+
+// LatestIteration returns the ID of the latest iteration of a pull request.
+func (c *Client) LatestIteration(ctx context.Context, prID int) (int, error) {
+	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/iterations?api-version=7.1-preview.1",
+		c.baseURL, c.org, c.project, c.repo, prID)
+	var response struct {
+		Value []struct {
+			ID int `json:"id"`
+		} `json:"value"`
+	}
+	if err := c.get(ctx, url, &response); err != nil {
+		return 0, fmt.Errorf("listing iterations for pull request %d: %w", prID, err)
+	}
+	var id int
+	for _, it := range response.Value {
+		id = max(id, it.ID)
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("pull request %d has no iterations", prID)
+	}
+	return id, nil
+}
+
+// IterationChanges returns the file changes of a pull request iteration.
+func (c *Client) IterationChanges(ctx context.Context, prID, iterationID int) ([]IterationChange, error) {
+	var (
+		changes []IterationChange
+		u       = fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/iterations/%d/changes?api-version=7.1-preview.1",
+			c.baseURL, c.org, c.project, c.repo, prID, iterationID)
+	)
+	// Changes are returned in pages, with the offset
+	// of the next page set in the nextSkip field.
+	for skip := 0; ; {
+		var response struct {
+			ChangeEntries []IterationChange `json:"changeEntries"`
+			NextSkip      int               `json:"nextSkip"`
+		}
+		pageURL := fmt.Sprintf("%s&$top=%d&$skip=%d", u, iterationChangesPageSize, skip)
+		if err := c.get(ctx, pageURL, &response); err != nil {
+			return nil, fmt.Errorf("listing changes of iteration %d for pull request %d: %w", iterationID, prID, err)
+		}
+		changes = append(changes, response.ChangeEntries...)
+		if response.NextSkip <= skip || len(response.ChangeEntries) == 0 {
+			return changes, nil
+		}
+		skip = response.NextSkip
+	}
+}
+
 // AddCommentToThread adds a comment to an existing comment thread.
 func (c *Client) AddCommentToThread(ctx context.Context, prID, threadID int, content string) (*Comment, error) {
 	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads/%d/comments?api-version=7.1-preview.1",
@@ -263,27 +421,82 @@ func (c *Client) UpdateFirstComment(ctx context.Context, prID, threadID int, con
 
 // ListCommentThreads retrieves all comment threads for a pull request.
 func (c *Client) ListCommentThreads(ctx context.Context, prID int) ([]CommentThread, error) {
-	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads?api-version=7.1-preview.1",
-		c.baseURL, c.org, c.project, c.repo, prID)
+	var (
+		threads []CommentThread
+		u       = fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads?api-version=7.1-preview.1",
+			c.baseURL, c.org, c.project, c.repo, prID)
+	)
+	// Large lists are returned in pages, with the token
+	// of the next page set in the x-ms-continuationtoken header.
+	for token := ""; ; {
+		pageURL := u
+		if token != "" {
+			pageURL += "&continuationToken=" + url.QueryEscape(token)
+		}
+		res, err := c.json(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing comment threads for pull request %d: %w", prID, err)
+		}
+		buf, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response for comment threads list: %w", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %d when listing comment threads. body: %s", res.StatusCode, string(buf))
+		}
+		var response struct {
+			Value []CommentThread `json:"value"`
+		}
+		if err = json.Unmarshal(buf, &response); err != nil {
+			return nil, fmt.Errorf("parsing comment threads list response: %w", err)
+		}
+		threads = append(threads, response.Value...)
+		if token = res.Header.Get("x-ms-continuationtoken"); token == "" {
+			return threads, nil
+		}
+	}
+}
+
+// SourceBranch returns the name of the source branch of the pull request.
+func (p *PullRequest) SourceBranch() string {
+	return strings.TrimPrefix(p.SourceRefName, "refs/heads/")
+}
+
+// URL returns the web URL of the pull request.
+func (p *PullRequest) URL() string {
+	if p.Repository.WebURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/pullrequest/%d", p.Repository.WebURL, p.ID)
+}
+
+// branchRef returns the ref of the given branch name.
+func branchRef(name string) string {
+	if strings.HasPrefix(name, "refs/") {
+		return name
+	}
+	return "refs/heads/" + name
+}
+
+// get sends a GET request to the Azure DevOps API and decodes the response into v.
+func (c *Client) get(ctx context.Context, url string, v any) error {
 	res, err := c.json(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("listing comment threads for pull request %d: %w", prID, err)
+		return err
 	}
 	defer res.Body.Close()
 	buf, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response for comment threads list: %w", err)
+		return fmt.Errorf("reading response body: %w", err)
 	}
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d when listing comment threads. body: %s", res.StatusCode, string(buf))
-	}
-	var response struct {
-		Value []CommentThread `json:"value"`
+		return fmt.Errorf("unexpected status code %d when calling Azure DevOps API. body: %s", res.StatusCode, string(buf))
 	}
-	if err = json.Unmarshal(buf, &response); err != nil {
-		return nil, fmt.Errorf("parsing comment threads list response: %w", err)
+	if err = json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("parsing response body: %w", err)
 	}
-	return response.Value, nil
+	return nil
 }
 
 // json sends a JSON request to the Azure DevOps API.
@@ -40,6 +40,32 @@ func TestGetPullRequest(t *testing.T) {
 	})
 }
 
+func TestCreatePullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/myorg/myproject/_apis/git/repositories/myrepo/pullrequests", r.URL.Path)
+		var req CreatePullRequestRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, CreatePullRequestRequest{
+			SourceRefName: "refs/heads/atlas-generated",
+			TargetRefName: "refs/heads/feature",
+			Title:         "add schema test",
+			Description:   "body",
+		}, req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"pullRequestId":7,"sourceRefName":"refs/heads/atlas-generated","repository":{"webUrl":"https://dev.azure.com/myorg/myproject/_git/myrepo"}}`))
+	}))
+	defer srv.Close()
+	client, err := NewClient("myorg", "myproject", "myrepo", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	pr, err := client.CreatePullRequest(context.Background(), "atlas-generated", "refs/heads/feature", "add schema test", "body")
+	require.NoError(t, err)
+	require.Equal(t, 7, pr.ID)
+	require.Equal(t, "atlas-generated", pr.SourceBranch())
+	require.Equal(t, "https://dev.azure.com/myorg/myproject/_git/myrepo/pullrequest/7", pr.URL())
+}
+
 func TestClient(t *testing.T) {
 	t.Run("end-to-end with authentication", func(t *testing.T) {
 		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -356,3 +382,47 @@ func TestUpdateFirstComment(t *testing.T) {
 		require.Contains(t, err.Error(), "getting thread to update first comment")
 	})
 }
+
+func TestListCommentThreads_Pagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/myorg/myproject/_apis/git/repositories/myrepo/pullrequests/123/threads", r.URL.Path)
+		switch r.URL.Query().Get("continuationToken") {
+		case "":
+			w.Header().Set("x-ms-continuationtoken", "next+token")
+			w.Write([]byte(`{"value":[{"id":1}]}`))
+		case "next+token":
+			w.Write([]byte(`{"value":[{"id":2}]}`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient("myorg", "myproject", "myrepo")
+	require.NoError(t, err)
+	client.baseURL = srv.URL
+	threads, err := client.ListCommentThreads(context.Background(), 123)
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	require.Equal(t, 1, threads[0].ID)
+	require.Equal(t, 2, threads[1].ID)
+}
+
+func TestIterationChanges_Pagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/myorg/myproject/_apis/git/repositories/myrepo/pullrequests/123/iterations/2/changes", r.URL.Path)
+		require.Equal(t, "100", r.URL.Query().Get("$top"))
+		switch r.URL.Query().Get("$skip") {
+		case "0":
+			w.Write([]byte(`{"changeEntries":[{"changeType":"add","item":{"path":"/migrations/1.sql"}}],"nextSkip":100,"nextTop":100}`))
+		case "100":
+			w.Write([]byte(`{"changeEntries":[{"changeType":"edit","item":{"path":"/migrations/2.sql"}}],"nextSkip":0,"nextTop":0}`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient("myorg", "myproject", "myrepo")
+	require.NoError(t, err)
+	client.baseURL = srv.URL
+	changes, err := client.IterationChanges(context.Background(), 123, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, "/migrations/1.sql", changes[0].Item.Path)
+	require.Equal(t, "/migrations/2.sql", changes[1].Item.Path)
+}
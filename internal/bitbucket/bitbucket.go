@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"time"
 
+	"ariga.io/atlas-action/internal/httpretry"
 	"golang.org/x/oauth2"
 )
 
@@ -70,6 +71,32 @@ type (
 	PullRequestComment struct {
 		Content Rendered `json:"content"`
 		ID      int      `json:"id,omitempty"`
+		Deleted bool     `json:"deleted,omitempty"`
+	}
+	// PullRequest is a pull request.
+	PullRequest struct {
+		ID          int          `json:"id,omitempty"`
+		Title       string       `json:"title"`
+		Description string       `json:"description,omitempty"`
+		State       string       `json:"state,omitempty"`
+		Source      BranchCommit `json:"source"`
+		Destination BranchCommit `json:"destination"`
+		Links       *struct {
+			HTML Link `json:"html"`
+		} `json:"links,omitempty"`
+	}
+	// BranchCommit is the source or destination of a pull request.
+	BranchCommit struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+		Commit *struct {
+			Hash string `json:"hash"`
+		} `json:"commit,omitempty"`
+	}
+	// Link is a link to a resource.
+	Link struct {
+		Href string `json:"href"`
 	}
 	Rendered struct {
 		Raw    string `json:"raw"`
@@ -132,6 +159,14 @@ const (
 // DefaultBaseURL is the default base URL for the Bitbucket API.
 const DefaultBaseURL = "https://api.bitbucket.org/2.0"
 
+// WithBaseURL returns a ClientOption that sets the base URL for the client.
+func WithBaseURL(u string) ClientOption {
+	return func(c *Client) error {
+		c.baseURL = u
+		return nil
+	}
+}
+
 // WithToken returns a ClientOption that sets the token for the client.
 func WithToken(t *oauth2.Token) ClientOption {
 	return func(c *Client) error {
@@ -154,9 +189,9 @@ func WithProxy(proxyFn func() (*url.URL, error)) ClientOption {
 		if err != nil {
 			return err
 		}
-		c.client.Transport = &http.Transport{
+		c.client = httpretry.NewClient(&http.Transport{
 			Proxy: http.ProxyURL(proxy),
-		}
+		})
 		u, err := url.Parse(c.baseURL)
 		if err != nil {
 			return err
@@ -174,7 +209,7 @@ func NewClient(workspace, repoSlug string, opts ...ClientOption) (*Client, error
 		workspace: workspace,
 		repoSlug:  repoSlug,
 		baseURL:   DefaultBaseURL,
-		client:    &http.Client{Timeout: time.Second * 30},
+		client:    httpretry.NewClient(nil),
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -278,6 +313,60 @@ func (b *Client) PullRequestUpdateComment(ctx context.Context, prID, id int, raw
 	return responseDecode[PullRequestComment](res, http.StatusOK)
 }
 
+// PullRequestDeleteComment deletes a comment on a pull request.
+func (b *Client) PullRequestDeleteComment(ctx context.Context, prID, id int) error {
+	u, err := b.repoURL("pullrequests", strconv.Itoa(prID), "comments", strconv.Itoa(id))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		_, err = responseDecode[struct{}](res, http.StatusNoContent)
+	}
+	return err
+}
+
+// PullRequest returns the pull request with the given ID.
+func (b *Client) PullRequest(ctx context.Context, prID int) (*PullRequest, error) {
+	u, err := b.repoURL("pullrequests", strconv.Itoa(prID))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return responseDecode[PullRequest](res, http.StatusOK)
+}
+
+// CreatePullRequest creates a pull request from the source branch into the destination branch.
+func (b *Client) CreatePullRequest(ctx context.Context, source, destination, title, description string) (*PullRequest, error) {
+	u, err := b.repoURL("pullrequests")
+	if err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{Title: title, Description: description}
+	pr.Source.Branch.Name = source
+	pr.Destination.Branch.Name = destination
+	res, err := b.json(ctx, http.MethodPost, u, pr)
+	if err != nil {
+		return nil, err
+	}
+	return responseDecode[PullRequest](res, http.StatusCreated)
+}
+
 func (b *Client) repoURL(elems ...string) (string, error) {
 	return url.JoinPath(b.baseURL, append([]string{"repositories", b.workspace, b.repoSlug}, elems...)...)
 }
@@ -0,0 +1,48 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestPullRequest(t *testing.T) {
+	m := http.NewServeMux()
+	m.HandleFunc("GET /repositories/ariga/atlas/pullrequests/1", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":1,"title":"Add users","description":"body","source":{"branch":{"name":"feature"},"commit":{"hash":"abc"}},"destination":{"branch":{"name":"main"}},"links":{"html":{"href":"https://bitbucket.org/ariga/atlas/pull-requests/1"}}}`))
+	})
+	m.HandleFunc("POST /repositories/ariga/atlas/pullrequests", func(w http.ResponseWriter, r *http.Request) {
+		var pr PullRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pr))
+		require.Equal(t, "feature", pr.Source.Branch.Name)
+		require.Equal(t, "main", pr.Destination.Branch.Name)
+		require.Equal(t, "Add users", pr.Title)
+		require.Nil(t, pr.Links)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":2,"title":"Add users","source":{"branch":{"name":"feature"}}}`))
+	})
+	m.HandleFunc("DELETE /repositories/ariga/atlas/pullrequests/1/comments/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	c, err := NewClient("ariga", "atlas", WithBaseURL(srv.URL), WithToken(&oauth2.Token{AccessToken: "token"}))
+	require.NoError(t, err)
+	ctx := context.Background()
+	pr, err := c.PullRequest(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "body", pr.Description)
+	require.Equal(t, "feature", pr.Source.Branch.Name)
+	require.Equal(t, "abc", pr.Source.Commit.Hash)
+	require.Equal(t, "https://bitbucket.org/ariga/atlas/pull-requests/1", pr.Links.HTML.Href)
+	pr, err = c.CreatePullRequest(ctx, "feature", "main", "Add users", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, pr.ID)
+	require.NoError(t, c.PullRequestDeleteComment(ctx, 1, 3))
+}
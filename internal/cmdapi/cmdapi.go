@@ -54,8 +54,12 @@ func Main(ctx context.Context, version, commit string) int {
 
 // RunActionCmd is a command to run one of the Atlas GitHub Actions.
 type RunActionCmd struct {
-	Action  string           `help:"Command to run" required:"" env:"ATLAS_ACTION"`
+	Action  string           `help:"Command to run" required:"" env:"ATLAS_ACTION,PLUGIN_ACTION"`
 	Version kong.VersionFlag `name:"version" help:"Print version information and quit"`
+	// Flags below are used only when running outside a CI.
+	Inputs      map[string]string `name:"input" help:"Input of the action in the form of key=value, when running locally"`
+	InputsFile  string            `name:"inputs-file" help:"Path to a JSON or YAML file with the inputs of the action, when running locally" type:"path"`
+	OutputsFile string            `name:"outputs-file" help:"Path to the JSON file to write the outputs of the action to, when running locally" type:"path"`
 }
 
 func (r *RunActionCmd) Run(ctx context.Context, a *atlasaction.Actions) error {
@@ -63,5 +67,14 @@ func (r *RunActionCmd) Run(ctx context.Context, a *atlasaction.Actions) error {
 	defer func() {
 		_ = os.Unsetenv("ATLAS_ACTION_COMMAND")
 	}()
+	if l, ok := a.Action.(*atlasaction.Local); ok {
+		l.Inputs = r.Inputs
+		if r.InputsFile != "" {
+			l.InputsFile = r.InputsFile
+		}
+		if r.OutputsFile != "" {
+			l.OutputsFile = r.OutputsFile
+		}
+	}
 	return a.Run(ctx, r.Action)
 }
@@ -0,0 +1,298 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"ariga.io/atlas-action/internal/httpretry"
+)
+
+type (
+	// Client is a client for the Gitea (and Forgejo) API.
+	Client struct {
+		baseURL string
+		repo    string
+		client  *http.Client
+	}
+	// ClientOption is the option when creating a new client.
+	ClientOption func(*Client) error
+	IssueComment struct {
+		ID   int    `json:"id"`
+		Body string `json:"body"`
+	}
+	PullRequest struct {
+		Number int
+		URL    string
+		Body   string
+		Commit string
+		Ref    string
+	}
+	// Token is a http.RoundTripper that sets the access token on the requests.
+	Token struct {
+		Token string
+		Base  http.RoundTripper
+	}
+)
+
+const DefaultBaseURL = "https://gitea.com/api/v1"
+
+// WithBaseURL returns a ClientOption that sets the base URL for the client.
+func WithBaseURL(url string) ClientOption {
+	return func(c *Client) error {
+		c.baseURL = url
+		return nil
+	}
+}
+
+// WithToken returns a ClientOption that sets the token for the client.
+func WithToken(token string) ClientOption {
+	return func(c *Client) error {
+		c.client.Transport = &Token{Token: token, Base: c.client.Transport}
+		return nil
+	}
+}
+
+// NewClient returns a new Gitea client for the given repository.
+func NewClient(repo string, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		repo:    repo,
+		baseURL: DefaultBaseURL,
+		client:  httpretry.NewClient(nil),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.baseURL == "" {
+		c.baseURL = DefaultBaseURL
+	}
+	return c, nil
+}
+
+// IssueComments returns the comments of the given issue or pull request.
+func (c *Client) IssueComments(ctx context.Context, prID int) ([]IssueComment, error) {
+	var comments []IssueComment
+	url := fmt.Sprintf("%v/repos/%v/issues/%v/comments?limit=50", c.baseURL, c.repo, prID)
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error querying gitea comments with %v/%v, %w", c.repo, prID, err)
+		}
+		buf, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading PR issue comments from %v/%v, %v", c.repo, prID, err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %v when calling Gitea API. body: %s", res.StatusCode, string(buf))
+		}
+		var page []IssueComment
+		if err = json.Unmarshal(buf, &page); err != nil {
+			return nil, fmt.Errorf("error parsing gitea comments with %v/%v from %v, %w", c.repo, prID, string(buf), err)
+		}
+		comments = append(comments, page...)
+		url = nextPage(res.Header.Get("Link"))
+	}
+	return comments, nil
+}
+
+// nextPage returns the URL of the next page from the given Link header, or
+// an empty string if there is no next page. For example:
+//
+//	<https://gitea.com/api/v1/repos/a/b/issues/1/comments?limit=50&page=2>; rel="next", <...>; rel="last"
+func nextPage(link string) string {
+	for l := range strings.SplitSeq(link, ",") {
+		u, rel, ok := strings.Cut(l, ";")
+		if ok && strings.TrimSpace(rel) == `rel="next"` {
+			return strings.Trim(strings.TrimSpace(u), "<>")
+		}
+	}
+	return ""
+}
+
+// CreateIssueComment creates a comment on the given issue or pull request.
+func (c *Client) CreateIssueComment(ctx context.Context, prID int, comment string) error {
+	content := strings.NewReader(fmt.Sprintf(`{"body":%q}`, comment))
+	url := fmt.Sprintf("%v/repos/%v/issues/%v/comments", c.baseURL, c.repo, prID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, content)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
+		}
+		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	return nil
+}
+
+// UpdateIssueComment updates issue comment with the given id.
+func (c *Client) UpdateIssueComment(ctx context.Context, id int, comment string) error {
+	content := strings.NewReader(fmt.Sprintf(`{"body":%q}`, comment))
+	url := fmt.Sprintf("%v/repos/%v/issues/comments/%v", c.baseURL, c.repo, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, content)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
+		}
+		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	return nil
+}
+
+// DeleteIssueComment deletes the issue comment with the given id.
+func (c *Client) DeleteIssueComment(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%v/repos/%v/issues/comments/%v", c.baseURL, c.repo, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
+		}
+		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	return nil
+}
+
+type pullRequest struct {
+	Number int    `json:"number"`
+	URL    string `json:"html_url"`
+	Body   string `json:"body"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+func (p pullRequest) PullRequest() *PullRequest {
+	return &PullRequest{
+		Number: p.Number,
+		URL:    p.URL,
+		Body:   p.Body,
+		Commit: p.Head.SHA,
+		Ref:    p.Head.Ref,
+	}
+}
+
+// PullRequest returns information about a pull request.
+func (c *Client) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d", c.baseURL, c.repo, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling Gitea API: %w", err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	var pr pullRequest
+	if err = json.Unmarshal(b, &pr); err != nil {
+		return nil, fmt.Errorf("unmarshalling response body: %w", err)
+	}
+	return pr.PullRequest(), nil
+}
+
+// CreatePullRequest creates a new pull request.
+func (c *Client) CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls", c.baseURL, c.repo)
+	j, err := json.Marshal(map[string]string{
+		"head":  head,
+		"base":  base,
+		"title": title,
+		"body":  body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling pull request data: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(j))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling Gitea API: %w", err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	var pr pullRequest
+	if err = json.Unmarshal(b, &pr); err != nil {
+		return nil, fmt.Errorf("unmarshalling response body: %w", err)
+	}
+	return pr.PullRequest(), nil
+}
+
+func (t *Token) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "token "+t.Token)
+	return t.base().RoundTrip(req)
+}
+
+func (t *Token) CancelRequest(req *http.Request) {
+	type canceler interface {
+		CancelRequest(*http.Request)
+	}
+	if tr, ok := t.Base.(canceler); ok {
+		tr.CancelRequest(req)
+	}
+}
+
+func (t *Token) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
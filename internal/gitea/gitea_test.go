@@ -0,0 +1,76 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteIssueComment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		require.Equal(t, "/repos/ariga/atlas/issues/comments/1", r.URL.Path)
+		require.Equal(t, "token token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	c, err := NewClient("ariga/atlas", WithBaseURL(srv.URL), WithToken("token"))
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteIssueComment(context.Background(), 1))
+}
+
+func TestIssueComments_Pagination(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/repos/ariga/atlas/issues/1/comments", r.URL.Path)
+		require.Equal(t, "50", r.URL.Query().Get("limit"))
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/ariga/atlas/issues/1/comments?limit=50&page=2>; rel="next", <%[1]s/repos/ariga/atlas/issues/1/comments?limit=50&page=2>; rel="last"`, srv.URL))
+			w.Write([]byte(`[{"id":1,"body":"first"}]`))
+		case "2":
+			w.Write([]byte(`[{"id":2,"body":"second"}]`))
+		}
+	}))
+	defer srv.Close()
+	c, err := NewClient("ariga/atlas", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	comments, err := c.IssueComments(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, []IssueComment{{ID: 1, Body: "first"}, {ID: 2, Body: "second"}}, comments)
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/repos/ariga/atlas/pulls", r.URL.Path)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]string{
+			"head":  "feature",
+			"base":  "main",
+			"title": "title",
+			"body":  "body",
+		}, body)
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte(`{"number":1,"html_url":"https://gitea.com/ariga/atlas/pulls/1","head":{"ref":"feature","sha":"abc"}}`))
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+	c, err := NewClient("ariga/atlas", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	pr, err := c.CreatePullRequest(context.Background(), "feature", "main", "title", "body")
+	require.NoError(t, err)
+	require.Equal(t, &PullRequest{
+		Number: 1,
+		URL:    "https://gitea.com/ariga/atlas/pulls/1",
+		Commit: "abc",
+		Ref:    "feature",
+	}, pr)
+}
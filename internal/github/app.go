@@ -0,0 +1,148 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"ariga.io/atlas-action/internal/httpretry"
+	"golang.org/x/oauth2"
+)
+
+// appTokenSource is an oauth2.TokenSource that exchanges a JWT signed with
+// the private key of a GitHub App for an installation access token.
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/authenticating-as-a-github-app-installation
+type appTokenSource struct {
+	c              *Client // For reading the base URL, that may be set after this option.
+	client         *http.Client
+	appID          string
+	installationID int64
+	key            *rsa.PrivateKey
+}
+
+// WithAppCredentials returns a ClientOption that authenticates the client as an installation
+// of a GitHub App. The installation token is minted on first use and refreshed before it expires.
+func WithAppCredentials(appID string, installationID int64, privateKeyPEM []byte) ClientOption {
+	return func(c *Client) error {
+		if appID == "" || installationID == 0 {
+			return errors.New("github: app ID and installation ID are required")
+		}
+		key, err := parsePrivateKey(privateKeyPEM)
+		if err != nil {
+			return err
+		}
+		base := c.client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		src := &appTokenSource{
+			c:              c,
+			client:         httpretry.NewClient(nil),
+			appID:          appID,
+			installationID: installationID,
+			key:            key,
+		}
+		c.client.Transport = &oauth2.Transport{
+			Base: base,
+			// Installation tokens are valid for an hour. Refresh them a few
+			// minutes ahead, so long-running requests do not use an expired one.
+			Source: oauth2.ReuseTokenSourceWithExpiry(nil, src, 5*time.Minute),
+		}
+		return nil
+	}
+}
+
+// Token implements oauth2.TokenSource.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%v/app/installations/%v/access_tokens", s.c.baseURL, s.installationID)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: creating installation access token: %w", err)
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("github: reading installation access token: %w", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("github: unexpected status code %v when creating installation access token: %s", res.StatusCode, string(buf))
+	}
+	var t struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(buf, &t); err != nil {
+		return nil, fmt.Errorf("github: parsing installation access token: %w", err)
+	}
+	return &oauth2.Token{AccessToken: t.Token, Expiry: t.ExpiresAt}, nil
+}
+
+// jwt returns a JSON Web Token, signed with RS256, that authenticates the app.
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func (s *appTokenSource) jwt() (string, error) {
+	now := time.Now()
+	claims, err := json.Marshal(map[string]any{
+		// Issued 60 seconds in the past to allow for clock drift.
+		"iat": now.Add(-time.Minute).Unix(),
+		// The maximum expiration allowed by GitHub is 10 minutes.
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("github: signing app JWT: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA private key, in PKCS #1 form
+// as generated by GitHub, or in PKCS #8 form.
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("github: invalid app private key: no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("github: invalid app private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("github: invalid app private key: unexpected key type %T", key)
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAppCredentials(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var (
+		tokens int
+		expiry = time.Hour
+		m      = http.NewServeMux()
+	)
+	m.HandleFunc("POST /app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		require.True(t, ok)
+		parts := strings.Split(jwt, ".")
+		require.Len(t, parts, 3)
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig))
+		b, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var claims struct {
+			Iss string `json:"iss"`
+			Iat int64  `json:"iat"`
+			Exp int64  `json:"exp"`
+		}
+		require.NoError(t, json.Unmarshal(b, &claims))
+		require.Equal(t, "1234", claims.Iss)
+		require.Less(t, claims.Iat, claims.Exp)
+		tokens++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_%d","expires_at":%q}`, tokens, time.Now().Add(expiry).Format(time.RFC3339))
+	})
+	m.HandleFunc("DELETE /repos/owner/repo/issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, fmt.Sprintf("Bearer ghs_%d", tokens), r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	// The base URL may be set after the credentials.
+	client, err := NewClient("owner/repo", WithAppCredentials("1234", 42, pemKey), WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteIssueComment(context.Background(), 1))
+	require.NoError(t, client.DeleteIssueComment(context.Background(), 2))
+	require.Equal(t, 1, tokens, "token is reused until it expires")
+
+	// Tokens that are about to expire are refreshed.
+	expiry = time.Minute
+	client, err = NewClient("owner/repo", WithBaseURL(srv.URL), WithAppCredentials("1234", 42, pemKey))
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteIssueComment(context.Background(), 1))
+	require.NoError(t, client.DeleteIssueComment(context.Background(), 2))
+	require.Equal(t, 3, tokens)
+
+	// PKCS #8 keys are also accepted.
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	_, err = NewClient("owner/repo", WithAppCredentials("1234", 42, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})))
+	require.NoError(t, err)
+	_, err = NewClient("owner/repo", WithAppCredentials("1234", 42, []byte("invalid")))
+	require.ErrorContains(t, err, "invalid app private key")
+	_, err = NewClient("owner/repo", WithAppCredentials("1234", 0, pemKey))
+	require.ErrorContains(t, err, "installation ID are required")
+}
@@ -12,8 +12,8 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
+	"ariga.io/atlas-action/internal/httpretry"
 	"github.com/mitchellh/mapstructure"
 	"golang.org/x/oauth2"
 )
@@ -39,6 +39,14 @@ type (
 		StartLine int    `json:"start_line,omitempty"`
 		Line      int    `json:"line,omitempty"`
 	}
+	// PullRequestReview is a review of a pull request with its line comments.
+	// https://docs.github.com/en/rest/pulls/reviews#create-a-review-for-a-pull-request
+	PullRequestReview struct {
+		CommitID string                `json:"commit_id,omitempty"`
+		Body     string                `json:"body,omitempty"`
+		Event    string                `json:"event"` // APPROVE, REQUEST_CHANGES or COMMENT.
+		Comments []*PullRequestComment `json:"comments,omitempty"`
+	}
 	pullRequestFile struct {
 		Name string `json:"filename"`
 	}
@@ -49,6 +57,35 @@ type (
 		Commit string
 		Ref    string
 	}
+	// CheckRun is a GitHub check run.
+	// https://docs.github.com/en/rest/checks/runs
+	CheckRun struct {
+		ID         int64           `json:"id,omitempty"`
+		Name       string          `json:"name,omitempty"`
+		HeadSHA    string          `json:"head_sha,omitempty"`
+		Status     string          `json:"status,omitempty"`
+		Conclusion string          `json:"conclusion,omitempty"`
+		DetailsURL string          `json:"details_url,omitempty"`
+		URL        string          `json:"html_url,omitempty"`
+		Output     *CheckRunOutput `json:"output,omitempty"`
+	}
+	// CheckRunOutput is the output of a check run.
+	CheckRunOutput struct {
+		Title       string                `json:"title"`
+		Summary     string                `json:"summary"`
+		Text        string                `json:"text,omitempty"`
+		Annotations []*CheckRunAnnotation `json:"annotations,omitempty"`
+	}
+	// CheckRunAnnotation is a line-level annotation of a check run.
+	CheckRunAnnotation struct {
+		Path       string `json:"path"`
+		StartLine  int    `json:"start_line"`
+		EndLine    int    `json:"end_line"`
+		Level      string `json:"annotation_level"` // notice, warning or failure.
+		Title      string `json:"title,omitempty"`
+		Message    string `json:"message"`
+		RawDetails string `json:"raw_details,omitempty"`
+	}
 	// TriggerEvent is the structure of the GitHub trigger event.
 	TriggerEvent struct {
 		// Only in case of an 'issue_comment' event.
@@ -78,7 +115,12 @@ type (
 	}
 )
 
-const DefaultBaseURL = "https://api.github.com"
+const (
+	DefaultBaseURL = "https://api.github.com"
+	// MaxCheckRunAnnotations is the maximum number of annotations
+	// that can be sent in a single check run request.
+	MaxCheckRunAnnotations = 50
+)
 
 // WithBaseURL returns a ClientOption that sets the base URL for the client.
 func WithBaseURL(url string) ClientOption {
@@ -109,7 +151,7 @@ func NewClient(repo string, opts ...ClientOption) (*Client, error) {
 	c := &Client{
 		repo:    repo,
 		baseURL: DefaultBaseURL,
-		client:  &http.Client{Timeout: time.Second * 30},
+		client:  httpretry.NewClient(nil),
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -124,26 +166,10 @@ func NewClient(repo string, opts ...ClientOption) (*Client, error) {
 
 func (c *Client) IssueComments(ctx context.Context, prID int) ([]IssueComment, error) {
 	url := fmt.Sprintf("%v/repos/%v/issues/%v/comments", c.baseURL, c.repo, prID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.client.Do(req)
+	comments, err := list[IssueComment](ctx, c, url)
 	if err != nil {
 		return nil, fmt.Errorf("error querying github comments with %v/%v, %w", c.repo, prID, err)
 	}
-	defer res.Body.Close()
-	buf, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading PR issue comments from %v/%v, %v", c.repo, prID, err)
-	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %v when calling GitHub API", res.StatusCode)
-	}
-	var comments []IssueComment
-	if err = json.Unmarshal(buf, &comments); err != nil {
-		return nil, fmt.Errorf("error parsing github comments with %v/%v from %v, %w", c.repo, prID, string(buf), err)
-	}
 	return comments, nil
 }
 
@@ -217,32 +243,38 @@ func (c *Client) DeleteIssueComment(ctx context.Context, id int) error {
 // ReviewComments for the trigger event pull request.
 func (c *Client) ReviewComments(ctx context.Context, prID int) ([]PullRequestComment, error) {
 	url := fmt.Sprintf("%v/repos/%v/pulls/%v/comments", c.baseURL, c.repo, prID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	return list[PullRequestComment](ctx, c, url)
+}
+
+func (c *Client) CreateReviewComment(ctx context.Context, prID int, s *PullRequestComment) error {
+	url := fmt.Sprintf("%v/repos/%v/pulls/%v/comments", c.baseURL, c.repo, prID)
+	buf, err := json.Marshal(s)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
+	if err != nil {
+		return err
 	}
 	res, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
+	if res.StatusCode != http.StatusCreated {
 		b, err := io.ReadAll(res.Body)
 		if err != nil {
-			return nil, fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
+			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
 		}
-		return nil, fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
-	}
-	var comments []PullRequestComment
-	if err = json.NewDecoder(res.Body).Decode(&comments); err != nil {
-		return nil, err
+		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
 	}
-	return comments, nil
+	return err
 }
 
-func (c *Client) CreateReviewComment(ctx context.Context, prID int, s *PullRequestComment) error {
-	url := fmt.Sprintf("%v/repos/%v/pulls/%v/comments", c.baseURL, c.repo, prID)
-	buf, err := json.Marshal(s)
+// CreateReview creates a review on the pull request, with all its comments in one request.
+func (c *Client) CreateReview(ctx context.Context, prID int, r *PullRequestReview) error {
+	url := fmt.Sprintf("%v/repos/%v/pulls/%v/reviews", c.baseURL, c.repo, prID)
+	buf, err := json.Marshal(r)
 	if err != nil {
 		return err
 	}
@@ -255,14 +287,36 @@ func (c *Client) CreateReviewComment(ctx context.Context, prID int, s *PullReque
 		return err
 	}
 	defer res.Body.Close()
-	if res.StatusCode != http.StatusCreated {
+	if res.StatusCode != http.StatusOK {
 		b, err := io.ReadAll(res.Body)
 		if err != nil {
 			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
 		}
 		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
 	}
-	return err
+	return nil
+}
+
+// DeleteReviewComment deletes the review comment with the given id.
+func (c *Client) DeleteReviewComment(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%v/repos/%v/pulls/comments/%v", c.baseURL, c.repo, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
+		}
+		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	return nil
 }
 
 // UpdateReviewComment updates the review comment with the given id.
@@ -297,31 +351,60 @@ func (c *Client) UpdateReviewComment(ctx context.Context, id int, body string) e
 // ListPullRequestFiles return paths of the files in the trigger event pull request.
 func (c *Client) ListPullRequestFiles(ctx context.Context, prID int) ([]string, error) {
 	url := fmt.Sprintf("%v/repos/%v/pulls/%v/files", c.baseURL, c.repo, prID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	files, err := list[pullRequestFile](ctx, c, url)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
+	paths := make([]string, len(files))
+	for i := range files {
+		paths[i] = files[i].Name
 	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		b, err := io.ReadAll(res.Body)
+	return paths, nil
+}
+
+// list returns the items of all pages of the given list endpoint,
+// following the "next" relation of the Link header of the responses.
+func list[T any](ctx context.Context, c *Client, url string) ([]T, error) {
+	var items []T
+	for url += "?per_page=100"; url != ""; {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := io.ReadAll(res.Body)
+		res.Body.Close()
 		if err != nil {
 			return nil, fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
 		}
-		return nil, fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
-	}
-	var files []pullRequestFile
-	if err = json.NewDecoder(res.Body).Decode(&files); err != nil {
-		return nil, err
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(buf))
+		}
+		var page []T
+		if err = json.Unmarshal(buf, &page); err != nil {
+			return nil, fmt.Errorf("error parsing github response from %v, %w", string(buf), err)
+		}
+		items = append(items, page...)
+		url = nextPage(res.Header.Get("Link"))
 	}
-	paths := make([]string, len(files))
-	for i := range files {
-		paths[i] = files[i].Name
+	return items, nil
+}
+
+// nextPage returns the URL of the next page from the given Link header, or
+// an empty string if there is no next page. For example:
+//
+//	<https://api.github.com/repositories/1/issues/1/comments?page=2>; rel="next", <...>; rel="last"
+func nextPage(link string) string {
+	for l := range strings.SplitSeq(link, ",") {
+		u, rel, ok := strings.Cut(l, ";")
+		if ok && strings.TrimSpace(rel) == `rel="next"` {
+			return strings.Trim(strings.TrimSpace(u), "<>")
+		}
 	}
-	return paths, nil
+	return ""
 }
 
 // OpeningPullRequest returns the latest open pull request for the given branch.
@@ -450,6 +533,69 @@ func (c *Client) CreatePullRequest(ctx context.Context, head, base, title, body
 	return pr.PullRequest(), nil
 }
 
+// CreateCheckRun creates a new check run.
+func (c *Client) CreateCheckRun(ctx context.Context, run *CheckRun) (*CheckRun, error) {
+	url := fmt.Sprintf("%s/repos/%s/check-runs", c.baseURL, c.repo)
+	return c.checkRun(ctx, http.MethodPost, url, http.StatusCreated, run)
+}
+
+// UpdateCheckRun updates the check run with the given id.
+func (c *Client) UpdateCheckRun(ctx context.Context, id int64, run *CheckRun) (*CheckRun, error) {
+	url := fmt.Sprintf("%s/repos/%s/check-runs/%d", c.baseURL, c.repo, id)
+	return c.checkRun(ctx, http.MethodPatch, url, http.StatusOK, run)
+}
+
+// CompleteCheckRun completes the check run with the given conclusion and output.
+// The API accepts up to 50 annotations per request, so they are sent in batches,
+// and the last batch is sent along with the conclusion.
+func (c *Client) CompleteCheckRun(ctx context.Context, id int64, conclusion string, out *CheckRunOutput) (*CheckRun, error) {
+	annotations := out.Annotations
+	for len(annotations) > MaxCheckRunAnnotations {
+		batch := *out
+		batch.Annotations = annotations[:MaxCheckRunAnnotations]
+		if _, err := c.UpdateCheckRun(ctx, id, &CheckRun{Output: &batch}); err != nil {
+			return nil, err
+		}
+		annotations = annotations[MaxCheckRunAnnotations:]
+	}
+	last := *out
+	last.Annotations = annotations
+	return c.UpdateCheckRun(ctx, id, &CheckRun{
+		Status:     "completed",
+		Conclusion: conclusion,
+		Output:     &last,
+	})
+}
+
+func (c *Client) checkRun(ctx context.Context, method, url string, status int, run *CheckRun) (*CheckRun, error) {
+	buf, err := json.Marshal(run)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling check run: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling GitHub API: %w", err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	if res.StatusCode != status {
+		return nil, fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	var r CheckRun
+	if err = json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("unmarshalling response body: %w", err)
+	}
+	return &r, nil
+}
+
 func (c *Client) ownerRepo() (string, string, error) {
 	s := strings.Split(c.repo, "/")
 	if len(s) != 2 {
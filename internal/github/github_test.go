@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -20,3 +21,122 @@ func TestDeleteIssueComment(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, client.DeleteIssueComment(context.Background(), 123))
 }
+
+func TestCompleteCheckRun(t *testing.T) {
+	var reqs []*CheckRun
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, "/repos/owner/repo/check-runs/42", r.URL.Path)
+		var run CheckRun
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&run))
+		reqs = append(reqs, &run)
+		run.ID = 42
+		require.NoError(t, json.NewEncoder(w).Encode(run))
+	}))
+	defer srv.Close()
+	client, err := NewClient("owner/repo", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	out := &CheckRunOutput{Title: "Atlas", Summary: "120 issues found"}
+	for i := range 120 {
+		out.Annotations = append(out.Annotations, &CheckRunAnnotation{
+			Path:      "migrations/1.sql",
+			StartLine: i + 1,
+			EndLine:   i + 1,
+			Level:     "warning",
+			Message:   "issue",
+		})
+	}
+	run, err := client.CompleteCheckRun(context.Background(), 42, "neutral", out)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), run.ID)
+	require.Len(t, reqs, 3)
+	require.Len(t, reqs[0].Output.Annotations, 50)
+	require.Empty(t, reqs[0].Status)
+	require.Len(t, reqs[1].Output.Annotations, 50)
+	require.Len(t, reqs[2].Output.Annotations, 20)
+	require.Equal(t, 101, reqs[2].Output.Annotations[0].StartLine)
+	require.Equal(t, "completed", reqs[2].Status)
+	require.Equal(t, "neutral", reqs[2].Conclusion)
+	require.Equal(t, "120 issues found", reqs[2].Output.Summary)
+}
+
+func TestCreateReview(t *testing.T) {
+	var reqs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqs = append(reqs, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodPost:
+			var review PullRequestReview
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+			require.Equal(t, "sha", review.CommitID)
+			require.Equal(t, "COMMENT", review.Event)
+			require.Len(t, review.Comments, 2)
+			require.Equal(t, "migrations/1.sql", review.Comments[0].Path)
+			require.Equal(t, 2, review.Comments[1].StartLine)
+			require.Equal(t, 3, review.Comments[1].Line)
+			w.WriteHeader(http.StatusOK)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient("owner/repo", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	require.NoError(t, client.CreateReview(context.Background(), 1, &PullRequestReview{
+		CommitID: "sha",
+		Event:    "COMMENT",
+		Comments: []*PullRequestComment{
+			{Path: "migrations/1.sql", Body: "fix", Line: 1},
+			{Path: "migrations/2.sql", Body: "fix", StartLine: 2, Line: 3},
+		},
+	}))
+	require.NoError(t, client.DeleteReviewComment(context.Background(), 42))
+	require.Equal(t, []string{
+		"POST /repos/owner/repo/pulls/1/reviews",
+		"DELETE /repos/owner/repo/pulls/comments/42",
+	}, reqs)
+}
+
+func TestIssueComments_Pagination(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/owner/repo/issues/1/comments", r.URL.Path)
+		switch r.URL.Query().Get("page") {
+		case "":
+			require.Equal(t, "100", r.URL.Query().Get("per_page"))
+			w.Header().Set("Link", `<`+srv.URL+`/repos/owner/repo/issues/1/comments?per_page=100&page=2>; rel="next", <`+srv.URL+`/repos/owner/repo/issues/1/comments?per_page=100&page=2>; rel="last"`)
+			w.Write([]byte(`[{"id":1,"body":"first"}]`))
+		case "2":
+			w.Header().Set("Link", `<`+srv.URL+`/repos/owner/repo/issues/1/comments?per_page=100&page=1>; rel="prev"`)
+			w.Write([]byte(`[{"id":2,"body":"second"}]`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient("owner/repo", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	comments, err := client.IssueComments(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, []IssueComment{{ID: 1, Body: "first"}, {ID: 2, Body: "second"}}, comments)
+}
+
+func TestClient_Retry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls++; calls {
+		case 1:
+			// Secondary rate limit.
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient("owner/repo", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteReviewComment(context.Background(), 1))
+	require.Equal(t, 3, calls)
+}
@@ -5,13 +5,15 @@
 package gitlab
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
+
+	"ariga.io/atlas-action/internal/httpretry"
 )
 
 type (
@@ -22,9 +24,45 @@ type (
 	}
 	ClientOption func(*Client) error
 	Note         struct {
-		ID     int    `json:"id"`
-		Body   string `json:"body"`
-		System bool   `json:"system"`
+		ID       int       `json:"id"`
+		Body     string    `json:"body"`
+		System   bool      `json:"system"`
+		Position *Position `json:"position,omitempty"`
+	}
+	// Discussion is a thread of notes on a merge request.
+	Discussion struct {
+		ID    string `json:"id"`
+		Notes []Note `json:"notes"`
+	}
+	// Position is the position of a diff note in a merge request.
+	Position struct {
+		BaseSHA      string `json:"base_sha"`
+		StartSHA     string `json:"start_sha"`
+		HeadSHA      string `json:"head_sha"`
+		PositionType string `json:"position_type"`
+		NewPath      string `json:"new_path,omitempty"`
+		NewLine      int    `json:"new_line,omitempty"`
+	}
+	// DiffRefs are the SHAs of the merge request diff.
+	DiffRefs struct {
+		BaseSHA  string `json:"base_sha"`
+		StartSHA string `json:"start_sha"`
+		HeadSHA  string `json:"head_sha"`
+	}
+	// MergeRequest is a GitLab merge request.
+	MergeRequest struct {
+		IID          int       `json:"iid"`
+		Title        string    `json:"title"`
+		Description  string    `json:"description"`
+		SourceBranch string    `json:"source_branch"`
+		TargetBranch string    `json:"target_branch"`
+		SHA          string    `json:"sha"`
+		WebURL       string    `json:"web_url"`
+		DiffRefs     *DiffRefs `json:"diff_refs,omitempty"`
+	}
+	mergeRequestDiff struct {
+		NewPath     string `json:"new_path"`
+		DeletedFile bool   `json:"deleted_file"`
 	}
 	PrivateToken struct {
 		Token string
@@ -54,7 +92,7 @@ func NewClient(project string, opts ...ClientOption) (*Client, error) {
 	c := &Client{
 		baseURL: DefaultBaseURL,
 		project: project,
-		client:  &http.Client{Timeout: time.Second * 30},
+		client:  httpretry.NewClient(nil),
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -69,27 +107,10 @@ func NewClient(project string, opts ...ClientOption) (*Client, error) {
 
 func (c *Client) PullRequestNotes(ctx context.Context, prID int) ([]Note, error) {
 	url := fmt.Sprintf("%v/projects/%v/merge_requests/%v/notes", c.baseURL, c.project, prID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	res, err := c.client.Do(req)
+	comments, err := list[Note](ctx, c, url)
 	if err != nil {
 		return nil, fmt.Errorf("error querying gitlab comments with %v/%v, %w", c.project, prID, err)
 	}
-	defer res.Body.Close()
-	buf, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading PR issue comments from %v/%v, %v", c.project, prID, err)
-	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %v when calling Gitlab API. body: %s", res.StatusCode, string(buf))
-	}
-	var comments []Note
-	if err = json.Unmarshal(buf, &comments); err != nil {
-		return nil, fmt.Errorf("error parsing gitlab notes with %v/%v from %v, %w", c.project, prID, string(buf), err)
-	}
 	return comments, nil
 }
 
@@ -161,6 +182,149 @@ func (c *Client) DeleteNote(ctx context.Context, prID int, noteID int) error {
 	return nil
 }
 
+// MergeRequest returns the merge request with the given IID.
+func (c *Client) MergeRequest(ctx context.Context, prID int) (*MergeRequest, error) {
+	url := fmt.Sprintf("%v/projects/%v/merge_requests/%v", c.baseURL, c.project, prID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error querying gitlab merge request with %v/%v, %w", c.project, prID, err)
+	}
+	return decodeMergeRequest(res, http.StatusOK)
+}
+
+// CreateMergeRequest creates a merge request from the source branch into the target branch.
+func (c *Client) CreateMergeRequest(ctx context.Context, source, target, title, description string) (*MergeRequest, error) {
+	body, err := json.Marshal(map[string]string{
+		"source_branch": source,
+		"target_branch": target,
+		"title":         title,
+		"description":   description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling merge request data: %w", err)
+	}
+	url := fmt.Sprintf("%v/projects/%v/merge_requests", c.baseURL, c.project)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gitlab merge request in %v, %w", c.project, err)
+	}
+	return decodeMergeRequest(res, http.StatusCreated)
+}
+
+// MergeRequestDiffPaths returns the new paths of the files changed in the merge request.
+func (c *Client) MergeRequestDiffPaths(ctx context.Context, prID int) ([]string, error) {
+	url := fmt.Sprintf("%v/projects/%v/merge_requests/%v/diffs", c.baseURL, c.project, prID)
+	diffs, err := list[mergeRequestDiff](ctx, c, url)
+	if err != nil {
+		return nil, fmt.Errorf("error querying gitlab merge request diffs with %v/%v, %w", c.project, prID, err)
+	}
+	paths := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		if !d.DeletedFile {
+			paths = append(paths, d.NewPath)
+		}
+	}
+	return paths, nil
+}
+
+// MergeRequestDiscussions returns the discussions of the merge request.
+func (c *Client) MergeRequestDiscussions(ctx context.Context, prID int) ([]Discussion, error) {
+	url := fmt.Sprintf("%v/projects/%v/merge_requests/%v/discussions", c.baseURL, c.project, prID)
+	discussions, err := list[Discussion](ctx, c, url)
+	if err != nil {
+		return nil, fmt.Errorf("error querying gitlab discussions with %v/%v, %w", c.project, prID, err)
+	}
+	return discussions, nil
+}
+
+// CreateDiscussion starts a new discussion on the merge request. If the position
+// is set, the discussion is attached to the given line of the merge request diff.
+func (c *Client) CreateDiscussion(ctx context.Context, prID int, body string, pos *Position) error {
+	b, err := json.Marshal(struct {
+		Body     string    `json:"body"`
+		Position *Position `json:"position,omitempty"`
+	}{Body: body, Position: pos})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%v/projects/%v/merge_requests/%v/discussions", c.baseURL, c.project, prID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("unexpected status code %v: unable to read body %v", res.StatusCode, err)
+		}
+		return fmt.Errorf("unexpected status code %v: with body: %v", res.StatusCode, string(b))
+	}
+	return nil
+}
+
+// list returns the items of all pages of the given list endpoint,
+// following the X-Next-Page header of the responses.
+func list[T any](ctx context.Context, c *Client, u string) ([]T, error) {
+	var items []T
+	for page := "1"; page != ""; {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?per_page=100&page=%s", u, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response body: %w", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %v when calling Gitlab API. body: %s", res.StatusCode, string(buf))
+		}
+		var p []T
+		if err := json.Unmarshal(buf, &p); err != nil {
+			return nil, fmt.Errorf("parsing response body %s: %w", string(buf), err)
+		}
+		items = append(items, p...)
+		page = res.Header.Get("X-Next-Page")
+	}
+	return items, nil
+}
+
+// decodeMergeRequest decodes the merge request from the response with the expected status code.
+func decodeMergeRequest(res *http.Response, status int) (*MergeRequest, error) {
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	if res.StatusCode != status {
+		return nil, fmt.Errorf("unexpected status code %v when calling Gitlab API. body: %s", res.StatusCode, string(buf))
+	}
+	var mr MergeRequest
+	if err = json.Unmarshal(buf, &mr); err != nil {
+		return nil, fmt.Errorf("error parsing gitlab merge request from %v, %w", string(buf), err)
+	}
+	return &mr, nil
+}
+
 func (t *PrivateToken) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Set("PRIVATE-TOKEN", t.Token)
 	return t.base().RoundTrip(req)
@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -21,3 +22,62 @@ func TestDeleteNote(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, client.DeleteNote(context.Background(), 2, 3))
 }
+
+func TestMergeRequest(t *testing.T) {
+	m := http.NewServeMux()
+	m.HandleFunc("GET /projects/1/merge_requests/2", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("PRIVATE-TOKEN"))
+		w.Write([]byte(`{"iid":2,"description":"body","source_branch":"feature","sha":"abc","web_url":"https://gitlab.com/ariga/atlas/-/merge_requests/2"}`))
+	})
+	m.HandleFunc("POST /projects/1/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]string{
+			"source_branch": "feature",
+			"target_branch": "main",
+			"title":         "Add tests",
+			"description":   "body",
+		}, body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"iid":3,"source_branch":"feature","web_url":"https://gitlab.com/ariga/atlas/-/merge_requests/3"}`))
+	})
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+	client, err := NewClient("1", WithBaseURL(srv.URL), WithToken("token"))
+	require.NoError(t, err)
+	mr, err := client.MergeRequest(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, &MergeRequest{
+		IID:          2,
+		Description:  "body",
+		SourceBranch: "feature",
+		SHA:          "abc",
+		WebURL:       "https://gitlab.com/ariga/atlas/-/merge_requests/2",
+	}, mr)
+	mr, err = client.CreateMergeRequest(context.Background(), "feature", "main", "Add tests", "body")
+	require.NoError(t, err)
+	require.Equal(t, 3, mr.IID)
+	_, err = client.MergeRequest(context.Background(), 4)
+	require.ErrorContains(t, err, "unexpected status code 404")
+}
+
+func TestPullRequestNotes_Pagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/projects/1/merge_requests/2/notes", r.URL.Path)
+		require.Equal(t, "100", r.URL.Query().Get("per_page"))
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			w.Write([]byte(`[{"id":1,"body":"first"}]`))
+		case "2":
+			w.Header().Set("X-Next-Page", "")
+			w.Write([]byte(`[{"id":2,"body":"second"}]`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient("1", WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	notes, err := client.PullRequestNotes(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, []Note{{ID: 1, Body: "first"}, {ID: 2, Body: "second"}}, notes)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package httpretry provides the retrying HTTP client shared by the SCM API clients.
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	// Timeout bounds a single attempt of a request.
+	Timeout = 30 * time.Second
+	// MaxRateLimitWait bounds the time to wait for a rate limit to reset.
+	MaxRateLimitWait = 5 * time.Minute
+)
+
+// NewClient returns an HTTP client that retries requests failed on connection errors,
+// server errors or rate limits. If base is nil, a pooled transport is used.
+func NewClient(base http.RoundTripper) *http.Client {
+	c := retryablehttp.NewClient()
+	c.Logger = nil
+	// Bounds a single attempt, as retryablehttp calls Do once per attempt.
+	c.HTTPClient.Timeout = Timeout
+	if base != nil {
+		c.HTTPClient.Transport = base
+	}
+	c.CheckRetry = CheckRetry
+	c.Backoff = Backoff
+	// Once the retries are exhausted, the last response is returned
+	// to the caller, so it can report the status code and body.
+	c.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	return c.StandardClient()
+}
+
+// CheckRetry extends the default retry policy (connection errors, 429 and 5xx)
+// with the 403 responses that GitHub returns when a rate limit is exceeded.
+func CheckRetry(ctx context.Context, res *http.Response, err error) (bool, error) {
+	if err == nil && ctx.Err() == nil && res.StatusCode == http.StatusForbidden && rateLimited(res) {
+		return true, nil
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, res, err)
+}
+
+// Backoff waits for the time advised by the Retry-After or the rate limit reset
+// headers of the response, and falls back to an exponential backoff otherwise.
+func Backoff(min, max time.Duration, attempt int, res *http.Response) time.Duration {
+	if d, ok := rateLimitWait(res); ok {
+		return d
+	}
+	return retryablehttp.DefaultBackoff(min, max, attempt, res)
+}
+
+// rateLimited reports if the response indicates that a rate limit was exceeded.
+func rateLimited(res *http.Response) bool {
+	return res.Header.Get("Retry-After") != "" || header(res, "X-RateLimit-Remaining", "RateLimit-Remaining") == "0"
+}
+
+// rateLimitWait returns the time to wait before retrying the request, as advised by the server.
+func rateLimitWait(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	var d time.Duration
+	switch v := res.Header.Get("Retry-After"); {
+	case v != "":
+		if s, err := strconv.Atoi(v); err == nil {
+			d = time.Duration(s) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			d = time.Until(t)
+		} else {
+			return 0, false
+		}
+	case header(res, "X-RateLimit-Remaining", "RateLimit-Remaining") == "0":
+		s, err := strconv.ParseInt(header(res, "X-RateLimit-Reset", "RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		d = time.Until(time.Unix(s, 0))
+	default:
+		return 0, false
+	}
+	return min(max(d, 0), MaxRateLimitWait), true
+}
+
+// header returns the value of the first of the given headers that is set.
+func header(res *http.Response, keys ...string) string {
+	for _, k := range keys {
+		if v := res.Header.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
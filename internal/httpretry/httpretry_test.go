@@ -0,0 +1,53 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package httpretry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRetry(t *testing.T) {
+	for _, tt := range []struct {
+		status int
+		header http.Header
+		retry  bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNotFound},
+		{status: http.StatusForbidden},
+		{status: http.StatusForbidden, header: http.Header{"Retry-After": {"60"}}, retry: true},
+		{status: http.StatusForbidden, header: http.Header{"X-Ratelimit-Remaining": {"0"}}, retry: true},
+		{status: http.StatusTooManyRequests, retry: true},
+		{status: http.StatusBadGateway, retry: true},
+	} {
+		retry, err := CheckRetry(context.Background(), &http.Response{StatusCode: tt.status, Header: tt.header}, nil)
+		require.NoError(t, err)
+		require.Equal(t, tt.retry, retry, tt.status)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	backoff := func(h http.Header) time.Duration {
+		return Backoff(time.Second, 30*time.Second, 0, &http.Response{StatusCode: http.StatusForbidden, Header: h})
+	}
+	require.Equal(t, 5*time.Second, backoff(http.Header{"Retry-After": {"5"}}))
+	require.Equal(t, MaxRateLimitWait, backoff(http.Header{"Retry-After": {"3600"}}))
+	reset := strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10)
+	d := backoff(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}})
+	require.True(t, d > 8*time.Second && d <= 10*time.Second, d)
+	// GitLab omits the X- prefix.
+	d = backoff(http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {reset}})
+	require.True(t, d > 8*time.Second && d <= 10*time.Second, d)
+	// Reset in the past.
+	require.Zero(t, backoff(http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1"}}))
+	// Exponential backoff without rate limit headers.
+	require.Equal(t, 4*time.Second, Backoff(time.Second, 30*time.Second, 2, &http.Response{StatusCode: http.StatusBadGateway}))
+}
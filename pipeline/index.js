@@ -0,0 +1 @@
+require('../shim/dist')('pipeline');